@@ -21,31 +21,79 @@ const (
 
 // Size constants for DHCP fields
 const (
-	SizeClientHardwareAddress    = 16
-	SizeServerHostName           = 64
-	SizeBootFileName             = 128
-	SizeMinimumDHCPMessageLength = 240
+	SizeClientHardwareAddress     = 16
+	SizeServerHostName            = 64
+	SizeBootFileName              = 128
+	SizeBOOTPHeader               = 236
+	SizeMinimumDHCPMessageLength  = 240
+	SizeMinimumBOOTPMessageLength = 300
+)
+
+// DHCPMagicCookie marks the start of the options field (RFC 1497, RFC 2131)
+const DHCPMagicCookie = 0x63825363
+
+// BOOTP op codes
+const (
+	BootRequest = 1
+	BootReply   = 2
 )
 
 // DHCP option constants
 const (
-	OptionDHCPMessageType      = 53
-	OptionClientIdentifier     = 61
-	OptionParameterRequestList = 55
-	OptionRequestedIPAddress   = 50
-	OptionServerIdentifier     = 54
-	OptionEnd                  = 255
-	OptionPad                  = 0
+	OptionSubnetMask               = 1
+	OptionRouter                   = 3
+	OptionDomainNameServer         = 6
+	OptionHostName                 = 12
+	OptionDomainName               = 15
+	OptionInterfaceMTU             = 26
+	OptionNTPServers               = 42
+	OptionVendorSpecific           = 43
+	OptionIPAddressLeaseTime       = 51
+	OptionOptionOverload           = 52
+	OptionMaximumMessageSize       = 57
+	OptionDHCPMessageType          = 53
+	OptionClientIdentifier         = 61
+	OptionTFTPServerName           = 66
+	OptionBootfileName             = 67
+	OptionUserClass                = 77
+	OptionRapidCommit              = 80
+	OptionClientFQDN               = 81
+	OptionRelayAgentInformation    = 82
+	OptionAuthentication           = 90
+	OptionClientSystemArchitecture = 93
+	OptionClientNDI                = 94
+	OptionClientMachineID          = 97
+	OptionPOSIXTimeZone            = 100
+	OptionTZDatabaseName           = 101
+	OptionIPv6OnlyPreferred        = 108
+	OptionCaptivePortal            = 114
+	OptionSZTPRedirect             = 143
+	OptionForceRenewNonceCapable   = 145
+	OptionParameterRequestList     = 55
+	OptionRenewalTime              = 58
+	OptionRebindingTime            = 59
+	OptionVendorClassIdentifier    = 60
+	OptionRequestedIPAddress       = 50
+	OptionServerIdentifier         = 54
+	OptionDomainSearch             = 119
+	OptionClasslessStaticRoute     = 121
+	OptionVIVendorClass            = 124
+	OptionVIVendorSpecific         = 125
+	OptionMSClasslessStaticRoute   = 249
+	OptionProxyAutoConfig          = 252
+	OptionEnd                      = 255
+	OptionPad                      = 0
 )
 
 // DHCP message type constants
 const (
-	DHCPDiscover = 1
-	DHCPOffer    = 2
-	DHCPRequest  = 3
-	DHCPDecline  = 4
-	DHCPAck      = 5
-	DHCPNak      = 6
-	DHCPRelease  = 7
-	DHCPInform   = 8
+	DHCPDiscover   = 1
+	DHCPOffer      = 2
+	DHCPRequest    = 3
+	DHCPDecline    = 4
+	DHCPAck        = 5
+	DHCPNak        = 6
+	DHCPRelease    = 7
+	DHCPInform     = 8
+	DHCPForceRenew = 9
 )
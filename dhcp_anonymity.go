@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// anonymize returns the configuration the RFC 7844 anonymity profile
+// allows. Settings that identify the client are dropped: the host name and
+// FQDN, vendor and user classes and extra options, and the features that
+// add to the parameter request list or send options of their own
+// (IPv6-only, captive portal, time, provisioning and FORCERENEW). The
+// client identifier is the hardware type and address, which is random
+// unless a stable per-network address is configured, and the parameter
+// request list is the anonymous profile.
+func (cfg ClientConfig) anonymize() (ClientConfig, error) {
+	if htype := cfg.hardwareType(); htype != HardwareTypeEthernet && htype != HardwareTypeIEEE802 {
+		return cfg, fmt.Errorf("anonymity mode requires a 48-bit MAC address, not %s", LookupHardwareType(htype).Name)
+	}
+	if cfg.PXE.Enabled {
+		return cfg, fmt.Errorf("anonymity mode cannot be combined with PXE, which sends a machine identifier")
+	}
+	if cfg.Authentication.Enabled {
+		return cfg, fmt.Errorf("anonymity mode cannot be combined with authentication, which identifies the client")
+	}
+
+	if cfg.MAC.Mode == MACModeHardware {
+		cfg.MAC.Mode = MACModeRandom
+	}
+	cfg.Hostname = ""
+	cfg.FQDN = ""
+	cfg.SendName = NameOptionNone
+	cfg.VendorClass = ""
+	cfg.UserClasses = nil
+	cfg.VIVendorClasses = nil
+	cfg.ExtraOptions = nil
+	cfg.ClientIdentifier = ClientIdentifierConfig{Mode: ClientIDMAC}
+	cfg.RequestProfile = RequestProfileAnonymous
+	cfg.RequestOptions = nil
+	cfg.IPv6OnlyPreferred = false
+	cfg.FetchCaptivePortal = false
+	cfg.FetchProxyAutoConfig = false
+	cfg.Time = TimeConfig{}
+	cfg.Provisioning = ProvisioningConfig{}
+	cfg.ForceRenew = false
+	return cfg, nil
+}
+
+// randomTransactionID returns a random xid, drawn afresh for every
+// exchange in anonymity mode so exchanges cannot be linked
+func randomTransactionID() uint32 {
+	var b [4]byte
+	rand.Read(b[:])
+	return binary.BigEndian.Uint32(b[:])
+}
+
+// HardwareAddress returns the link-layer address the client sends in
+// chaddr. When the address is generated, the interface must be set to the
+// same address for the privacy to hold.
+func (c *DHCPClient) HardwareAddress() net.HardwareAddr {
+	return net.HardwareAddr(append([]byte(nil), c.macAddr...))
+}
+
+// NetworkChanged tells the client it has moved to the network networkID.
+// The client forgets the lease, so the next exchange starts from INIT
+// rather than asking for the old address, and a generated hardware
+// address and client identifier are drawn again for the new network.
+func (c *DHCPClient) NetworkChanged(networkID string) error {
+	c.lease = nil
+	c.captivePortal = nil
+	c.proxyAutoConfig = ""
+	c.setState(StateInit)
+	c.config.MAC.NetworkID = networkID
+	if c.config.MAC.Mode == MACModeHardware {
+		return nil
+	}
+
+	macAddr, err := c.config.hardwareAddress(c.macAddr)
+	if err != nil {
+		return err
+	}
+	clientOptions, err := c.config.buildClientOptions(macAddr)
+	if err != nil {
+		return err
+	}
+	c.macAddr = macAddr
+	c.clientOptions = clientOptions
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestAnonymityModeOptions(t *testing.T) {
+	realMAC := []byte{0x00, 0x1b, 0x21, 0x3a, 0x4b, 0x5c}
+	client, err := NewDHCPClientWithConfig(realMAC, ClientConfig{
+		Anonymous:        true,
+		Hostname:         "alice-laptop",
+		FQDN:             "alice-laptop.example.com",
+		VendorClass:      "ExampleOS 1.0",
+		UserClasses:      []string{"staff"},
+		VIVendorClasses:  []VIVendorClass{{EnterpriseNumber: 9, Data: [][]byte{[]byte("model")}}},
+		ExtraOptions:     map[byte][]byte{224: []byte("serial-1234")},
+		ClientIdentifier: ClientIdentifierConfig{Mode: ClientIDOpaque, Opaque: []byte("alice")},
+		RequestOptions:   []string{"ntp-servers"},
+	})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	mac := client.HardwareAddress()
+	if bytes.Equal(mac, realMAC) {
+		t.Fatal("anonymity mode kept the real hardware address")
+	}
+	if mac[0]&0x01 != 0 || mac[0]&0x02 == 0 {
+		t.Fatalf("hardware address %s is not locally administered unicast", mac)
+	}
+
+	msg := client.createDHCPDiscover()
+	if got := msg.ClientHardwareAddress[:6]; !bytes.Equal(got, mac) {
+		t.Errorf("chaddr %x does not match the randomized address %s", got, mac)
+	}
+	if got, want := msg.Options[OptionClientIdentifier], append([]byte{HardwareTypeEthernet}, mac...); !bytes.Equal(got, want) {
+		t.Errorf("client identifier: got %x, want %x", got, want)
+	}
+	for _, code := range []byte{OptionHostName, OptionClientFQDN, OptionVendorClassIdentifier, OptionUserClass, OptionVIVendorClass, 224} {
+		if _, exists := msg.Options[code]; exists {
+			t.Errorf("option %d sent in anonymity mode", code)
+		}
+	}
+	if got, want := msg.Options[OptionParameterRequestList], requestProfiles[RequestProfileAnonymous]; !bytes.Equal(got, want) {
+		t.Errorf("parameter request list: got %v, want %v", got, want)
+	}
+}
+
+func TestAnonymityModeExactOptions(t *testing.T) {
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{
+		Anonymous:            true,
+		IPv6OnlyPreferred:    true,
+		FetchCaptivePortal:   true,
+		FetchProxyAutoConfig: true,
+		Time:                 TimeConfig{Request: true},
+		Provisioning:         ProvisioningConfig{StagingDir: t.TempDir()},
+		ForceRenew:           true,
+		MTU:                  9000,
+	})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	offer := &DHCPMessage{YourIP: 0x0a000005, Options: map[byte][]byte{
+		OptionDHCPMessageType:  {DHCPOffer},
+		OptionServerIdentifier: {10, 0, 0, 1},
+	}}
+	messages := map[string]struct {
+		msg  *DHCPMessage
+		want []byte
+	}{
+		"DHCPDISCOVER": {client.createDHCPDiscover(), []byte{OptionDHCPMessageType, OptionParameterRequestList, OptionClientIdentifier}},
+		"DHCPREQUEST": {client.createDHCPRequest(offer), []byte{OptionDHCPMessageType, OptionParameterRequestList, OptionClientIdentifier,
+			OptionRequestedIPAddress, OptionServerIdentifier}},
+	}
+
+	for name, m := range messages {
+		var got []byte
+		for code := range m.msg.Options {
+			got = append(got, code)
+		}
+		slices.Sort(got)
+		want := slices.Sorted(slices.Values(m.want))
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: options %v, want %v", name, got, want)
+		}
+		if prl := m.msg.Options[OptionParameterRequestList]; !bytes.Equal(prl, requestProfiles[RequestProfileAnonymous]) {
+			t.Errorf("%s: parameter request list %v, want %v", name, prl, requestProfiles[RequestProfileAnonymous])
+		}
+	}
+}
+
+func TestAnonymityModeRejectsIdentifyingFeatures(t *testing.T) {
+	configs := map[string]ClientConfig{
+		"PXE":            {Anonymous: true, PXE: PXEConfig{Enabled: true}},
+		"authentication": {Anonymous: true, Authentication: AuthConfig{Enabled: true, Keys: StaticKeyStore{}}},
+		"InfiniBand":     {Anonymous: true, HardwareType: HardwareTypeInfiniBand},
+	}
+
+	for name, cfg := range configs {
+		if _, err := cfg.anonymize(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestNetworkChanged(t *testing.T) {
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{Anonymous: true})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+	client.lease = &Lease{IP: netip.MustParseAddr("192.168.1.20")}
+	client.state = StateBound
+	before := client.HardwareAddress()
+
+	if err := client.NetworkChanged("guest-wifi"); err != nil {
+		t.Fatalf("NetworkChanged: %v", err)
+	}
+	if client.Lease() != nil || client.State() != StateInit {
+		t.Fatalf("expected INIT without a lease, got %s with %v", client.State(), client.Lease())
+	}
+	after := client.HardwareAddress()
+	if bytes.Equal(before, after) {
+		t.Fatal("hardware address was not re-randomized")
+	}
+	if got := client.createDHCPDiscover().Options[OptionClientIdentifier]; !bytes.Equal(got[1:], after) {
+		t.Fatalf("client identifier %x does not follow the new address %s", got, after)
+	}
+	if _, exists := client.createDHCPDiscover().Options[OptionRequestedIPAddress]; exists {
+		t.Fatal("DHCPDISCOVER asks for the old address")
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authentication protocols (RFC 3118 section 2, RFC 6704)
+const (
+	AuthProtocolConfigurationToken = 0
+	AuthProtocolDelayed            = 1
+	AuthProtocolReconfigureKey     = 3
+)
+
+// AuthAlgorithmHMACMD5 is the HMAC-MD5 algorithm used by delayed
+// authentication and reconfigure keys
+const AuthAlgorithmHMACMD5 = 1
+
+// AuthRDMCounter is the replay detection method using a monotonically
+// increasing counter
+const AuthRDMCounter = 0
+
+// Sizes of the fixed parts of option 90
+const (
+	sizeAuthHeader   = 11 // protocol, algorithm, RDM and replay detection
+	sizeAuthSecretID = 4
+	sizeAuthHMAC     = md5.Size
+)
+
+// Authentication is the content of option 90 (RFC 3118)
+type Authentication struct {
+	Protocol        byte
+	Algorithm       byte
+	RDM             byte
+	ReplayDetection uint64
+	// Info is the protocol-specific authentication information
+	Info []byte
+}
+
+// DecodeAuthentication decodes option 90
+func DecodeAuthentication(data []byte) (*Authentication, error) {
+	if len(data) < sizeAuthHeader {
+		return nil, fmt.Errorf("authentication option must be at least %d bytes, got %d", sizeAuthHeader, len(data))
+	}
+
+	return &Authentication{
+		Protocol:        data[0],
+		Algorithm:       data[1],
+		RDM:             data[2],
+		ReplayDetection: binary.BigEndian.Uint64(data[3:11]),
+		Info:            append([]byte(nil), data[11:]...),
+	}, nil
+}
+
+// Encode encodes the option 90 value
+func (a *Authentication) Encode() []byte {
+	data := []byte{a.Protocol, a.Algorithm, a.RDM}
+	data = binary.BigEndian.AppendUint64(data, a.ReplayDetection)
+	return append(data, a.Info...)
+}
+
+// SecretID returns the secret ID of a delayed authentication option
+func (a *Authentication) SecretID() (uint32, bool) {
+	if a.Protocol != AuthProtocolDelayed || len(a.Info) != sizeAuthSecretID+sizeAuthHMAC {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(a.Info), true
+}
+
+// String returns a human-readable representation of the option
+func (a *Authentication) String() string {
+	var protocol string
+	switch a.Protocol {
+	case AuthProtocolConfigurationToken:
+		protocol = "configuration token"
+	case AuthProtocolDelayed:
+		protocol = "delayed"
+	case AuthProtocolReconfigureKey:
+		protocol = "reconfigure key"
+	default:
+		protocol = fmt.Sprintf("protocol %d", a.Protocol)
+	}
+
+	result := fmt.Sprintf("%s, algorithm %d, RDM %d, replay 0x%016x", protocol, a.Algorithm, a.RDM, a.ReplayDetection)
+	if id, ok := a.SecretID(); ok {
+		return result + fmt.Sprintf(", secret ID %d, HMAC %s", id, hex.EncodeToString(a.Info[sizeAuthSecretID:]))
+	}
+	if len(a.Info) > 0 {
+		result += ", info " + hex.EncodeToString(a.Info)
+	}
+	return result
+}
+
+// KeyStore looks up delayed authentication secrets by secret ID
+type KeyStore interface {
+	Key(secretID uint32) ([]byte, bool)
+}
+
+// StaticKeyStore is a KeyStore backed by a map
+type StaticKeyStore map[uint32][]byte
+
+// Key returns the secret for secretID
+func (s StaticKeyStore) Key(secretID uint32) ([]byte, bool) {
+	key, exists := s[secretID]
+	return key, exists
+}
+
+// LoadKeyStore reads a key file with one "<secret ID> <hex key>" pair per
+// line. Blank lines and lines starting with '#' are ignored.
+func LoadKeyStore(path string) (StaticKeyStore, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open key file: %w", err)
+	}
+	defer file.Close()
+
+	keys := make(StaticKeyStore)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected secret ID and key", path, lineNumber)
+		}
+		id, err := strconv.ParseUint(fields[0], 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid secret ID: %w", path, lineNumber, err)
+		}
+		key, err := hex.DecodeString(fields[1])
+		if err != nil || len(key) == 0 {
+			return nil, fmt.Errorf("%s:%d: invalid key", path, lineNumber)
+		}
+		keys[uint32(id)] = key
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	return keys, nil
+}
+
+// authenticator keeps the delayed authentication state of a client
+type authenticator struct {
+	config AuthConfig
+
+	mu sync.Mutex
+	// replay is the last replay detection value the client sent
+	replay uint64
+	// secretID is the secret chosen by the server, learned from the first
+	// authenticated reply
+	secretID   uint32
+	haveSecret bool
+	// lastReplay is the highest replay detection value accepted from each
+	// secret, so older messages are rejected as replays
+	lastReplay map[uint32]uint64
+}
+
+// newAuthenticator creates the authentication state for a client
+func newAuthenticator(config AuthConfig) *authenticator {
+	return &authenticator{config: config, lastReplay: make(map[uint32]uint64)}
+}
+
+// nextReplay returns a replay detection value greater than any sent before.
+// Counters start from the current time so they keep increasing across
+// restarts.
+func (a *authenticator) nextReplay() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.replay = max(a.replay+1, uint64(time.Now().UnixNano()))
+	return a.replay
+}
+
+// option returns the option 90 value for an outgoing message. Until the
+// server has chosen a secret the option only requests delayed
+// authentication; afterwards it carries the secret ID and room for the
+// HMAC, which sign fills in.
+func (a *authenticator) option() []byte {
+	auth := &Authentication{
+		Protocol:        AuthProtocolDelayed,
+		Algorithm:       AuthAlgorithmHMACMD5,
+		RDM:             AuthRDMCounter,
+		ReplayDetection: a.nextReplay(),
+	}
+
+	a.mu.Lock()
+	if a.haveSecret {
+		auth.Info = binary.BigEndian.AppendUint32(nil, a.secretID)
+		auth.Info = append(auth.Info, make([]byte, sizeAuthHMAC)...)
+	}
+	a.mu.Unlock()
+
+	return auth.Encode()
+}
+
+// sign fills in the HMAC of the option 90 in a serialized packet
+func (a *authenticator) sign(packet []byte) error {
+	offset, length, found := findOption(packet, OptionAuthentication)
+	if !found {
+		return nil
+	}
+	auth, err := DecodeAuthentication(packet[offset : offset+length])
+	if err != nil {
+		return err
+	}
+	secretID, ok := auth.SecretID()
+	if !ok {
+		return nil
+	}
+
+	key, exists := a.config.Keys.Key(secretID)
+	if !exists {
+		return fmt.Errorf("no key for secret ID %d", secretID)
+	}
+
+	macOffset := offset + length - sizeAuthHMAC
+	copy(packet[macOffset:], authenticationHMAC(packet, macOffset, key))
+	return nil
+}
+
+// verify checks the authentication of a received message. Messages
+// without option 90 are only rejected when authentication is required.
+func (a *authenticator) verify(msg *DHCPMessage) error {
+	value, exists := msg.Options[OptionAuthentication]
+	if !exists {
+		if a.config.Required {
+			return fmt.Errorf("message is not authenticated")
+		}
+		return nil
+	}
+
+	auth, err := DecodeAuthentication(value)
+	if err != nil {
+		return err
+	}
+	if auth.Protocol == AuthProtocolReconfigureKey {
+		// RFC 6704 nonces are checked when a DHCPFORCERENEW arrives
+		if a.config.Required {
+			return fmt.Errorf("message is not authenticated")
+		}
+		return nil
+	}
+	if auth.Protocol != AuthProtocolDelayed || auth.Algorithm != AuthAlgorithmHMACMD5 || auth.RDM != AuthRDMCounter {
+		return fmt.Errorf("unsupported authentication: %s", auth)
+	}
+	secretID, ok := auth.SecretID()
+	if !ok {
+		if a.config.Required {
+			return fmt.Errorf("server did not authenticate the message")
+		}
+		return nil
+	}
+
+	key, exists := a.config.Keys.Key(secretID)
+	if !exists {
+		return fmt.Errorf("no key for secret ID %d", secretID)
+	}
+
+	offset, length, found := findOption(msg.raw, OptionAuthentication)
+	if !found || length != len(value) {
+		return fmt.Errorf("authentication option not found in packet")
+	}
+	macOffset := offset + length - sizeAuthHMAC
+	if !hmac.Equal(msg.raw[macOffset:offset+length], authenticationHMAC(msg.raw, macOffset, key)) {
+		return fmt.Errorf("HMAC mismatch for secret ID %d", secretID)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if last, seen := a.lastReplay[secretID]; seen && auth.ReplayDetection <= last {
+		return fmt.Errorf("replayed message: replay detection 0x%016x not above 0x%016x", auth.ReplayDetection, last)
+	}
+	a.lastReplay[secretID] = auth.ReplayDetection
+	a.secretID = secretID
+	a.haveSecret = true
+	return nil
+}
+
+// authenticationHMAC computes the HMAC-MD5 of a packet as RFC 3118 section
+// 5.4 describes: hops, giaddr and the HMAC field at macOffset are taken as
+// zero
+func authenticationHMAC(packet []byte, macOffset int, key []byte) []byte {
+	data := append([]byte(nil), packet...)
+	data[3] = 0                           // hops
+	copy(data[24:28], []byte{0, 0, 0, 0}) // giaddr
+	copy(data[macOffset:macOffset+sizeAuthHMAC], make([]byte, sizeAuthHMAC))
+
+	mac := hmac.New(md5.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// findOption returns the offset and length of the first instance of an
+// option in a serialized packet
+func findOption(packet []byte, code byte) (int, int, bool) {
+	if len(packet) < SizeMinimumDHCPMessageLength || binary.BigEndian.Uint32(packet[SizeBOOTPHeader:]) != DHCPMagicCookie {
+		return 0, 0, false
+	}
+
+	for i := SizeMinimumDHCPMessageLength; i < len(packet); {
+		switch packet[i] {
+		case OptionPad:
+			i++
+			continue
+		case OptionEnd:
+			return 0, 0, false
+		}
+		if i+1 >= len(packet) {
+			return 0, 0, false
+		}
+		length := int(packet[i+1])
+		if i+2+length > len(packet) {
+			return 0, 0, false
+		}
+		if packet[i] == code {
+			return i + 2, length, true
+		}
+		i += 2 + length
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testAuthKeys = StaticKeyStore{7: []byte("shared secret")}
+
+// signedReply builds a serialized server reply authenticated with secret
+// ID 7 and the given replay detection value
+func signedReply(t *testing.T, replay uint64, key []byte) []byte {
+	t.Helper()
+
+	info := binary.BigEndian.AppendUint32(nil, 7)
+	info = append(info, make([]byte, sizeAuthHMAC)...)
+	auth := &Authentication{
+		Protocol:        AuthProtocolDelayed,
+		Algorithm:       AuthAlgorithmHMACMD5,
+		ReplayDetection: replay,
+		Info:            info,
+	}
+
+	msg := &DHCPMessage{
+		OpCode:                BootReply,
+		HardwareType:          HardwareTypeEthernet,
+		HardwareAddressLength: 6,
+		TransactionID:         0x12345678,
+		YourIP:                0x0a000005,
+		ClientHardwareAddress: make([]byte, SizeClientHardwareAddress),
+		ServerHostName:        make([]byte, SizeServerHostName),
+		BootFileName:          make([]byte, SizeBootFileName),
+		MagicCookie:           DHCPMagicCookie,
+		Options: map[byte][]byte{
+			OptionDHCPMessageType:  {DHCPOffer},
+			OptionServerIdentifier: {10, 0, 0, 1},
+			OptionAuthentication:   auth.Encode(),
+		},
+	}
+	data, err := msg.Serialize()
+	if err != nil {
+		t.Fatalf("serialize: %v", err)
+	}
+
+	signer := newAuthenticator(AuthConfig{Enabled: true, Keys: StaticKeyStore{7: key}})
+	if err := signer.sign(data); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return data
+}
+
+func TestAuthenticationRoundTrip(t *testing.T) {
+	auth := &Authentication{
+		Protocol:        AuthProtocolDelayed,
+		Algorithm:       AuthAlgorithmHMACMD5,
+		RDM:             AuthRDMCounter,
+		ReplayDetection: 0x0102030405060708,
+		Info:            append([]byte{0, 0, 0, 7}, make([]byte, sizeAuthHMAC)...),
+	}
+
+	decoded, err := DecodeAuthentication(auth.Encode())
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded.ReplayDetection != auth.ReplayDetection || !bytes.Equal(decoded.Info, auth.Info) {
+		t.Fatalf("got %s, want %s", decoded, auth)
+	}
+	if id, ok := decoded.SecretID(); !ok || id != 7 {
+		t.Fatalf("secret ID = %d, %v", id, ok)
+	}
+
+	if _, err := DecodeAuthentication([]byte{1, 1, 0}); err == nil {
+		t.Fatal("expected error for truncated option")
+	}
+}
+
+func TestVerifyDelayedAuthentication(t *testing.T) {
+	a := newAuthenticator(AuthConfig{Enabled: true, Required: true, Keys: testAuthKeys})
+
+	msg, err := Deserialize(signedReply(t, 100, testAuthKeys[7]))
+	if err != nil {
+		t.Fatalf("deserialize: %v", err)
+	}
+	if err := a.verify(msg); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	// The same replay detection value is a replay
+	if err := a.verify(msg); err == nil {
+		t.Fatal("expected replayed message to be rejected")
+	}
+
+	// A relay changing hops and giaddr does not break the HMAC
+	data := signedReply(t, 101, testAuthKeys[7])
+	data[3] = 1
+	copy(data[24:28], []byte{10, 0, 0, 254})
+	msg, _ = Deserialize(data)
+	if err := a.verify(msg); err != nil {
+		t.Fatalf("verify relayed message: %v", err)
+	}
+
+	// Tampering with the message does
+	data = signedReply(t, 102, testAuthKeys[7])
+	data[16]++ // yiaddr
+	msg, _ = Deserialize(data)
+	if err := a.verify(msg); err == nil {
+		t.Fatal("expected tampered message to be rejected")
+	}
+
+	msg, _ = Deserialize(signedReply(t, 103, []byte("wrong key")))
+	if err := a.verify(msg); err == nil {
+		t.Fatal("expected message signed with the wrong key to be rejected")
+	}
+}
+
+func TestVerifyUnauthenticated(t *testing.T) {
+	msg := &DHCPMessage{Options: map[byte][]byte{OptionDHCPMessageType: {DHCPOffer}}}
+
+	optional := newAuthenticator(AuthConfig{Enabled: true, Keys: testAuthKeys})
+	if err := optional.verify(msg); err != nil {
+		t.Fatalf("unauthenticated message rejected when not required: %v", err)
+	}
+
+	required := newAuthenticator(AuthConfig{Enabled: true, Required: true, Keys: testAuthKeys})
+	if err := required.verify(msg); err == nil {
+		t.Fatal("expected unauthenticated message to be rejected when required")
+	}
+}
+
+func TestClientSignsRequestAfterAuthenticatedOffer(t *testing.T) {
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{
+		Authentication: AuthConfig{Enabled: true, Required: true, Keys: testAuthKeys},
+	})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	discover, err := DecodeAuthentication(client.createDHCPDiscover().Options[OptionAuthentication])
+	if err != nil {
+		t.Fatalf("decode DISCOVER authentication: %v", err)
+	}
+	if discover.Protocol != AuthProtocolDelayed || len(discover.Info) != 0 {
+		t.Fatalf("DISCOVER should only request delayed authentication, got %s", discover)
+	}
+
+	offer, _ := Deserialize(signedReply(t, 200, testAuthKeys[7]))
+	if err := client.auth.verify(offer); err != nil {
+		t.Fatalf("verify offer: %v", err)
+	}
+
+	data, err := client.createDHCPRequest(offer).Serialize()
+	if err != nil {
+		t.Fatalf("serialize: %v", err)
+	}
+	if err := client.auth.sign(data); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	// The server checks the REQUEST with the same key
+	request, _ := Deserialize(data)
+	server := newAuthenticator(AuthConfig{Enabled: true, Required: true, Keys: testAuthKeys})
+	if err := server.verify(request); err != nil {
+		t.Fatalf("server verify: %v", err)
+	}
+	if auth, _ := DecodeAuthentication(request.Options[OptionAuthentication]); auth.ReplayDetection <= discover.ReplayDetection {
+		t.Fatalf("replay detection did not increase: %d <= %d", auth.ReplayDetection, discover.ReplayDetection)
+	}
+}
+
+func TestLoadKeyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte("# id key\n7 736563726574\n\n0x10 00ff\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadKeyStore(path)
+	if err != nil {
+		t.Fatalf("LoadKeyStore: %v", err)
+	}
+	if key, ok := keys.Key(7); !ok || string(key) != "secret" {
+		t.Fatalf("key 7 = %q, %v", key, ok)
+	}
+	if key, ok := keys.Key(16); !ok || !bytes.Equal(key, []byte{0x00, 0xff}) {
+		t.Fatalf("key 16 = %x, %v", key, ok)
+	}
+
+	if err := os.WriteFile(path, []byte("7\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeyStore(path); err == nil {
+		t.Fatal("expected error for malformed line")
+	}
+}
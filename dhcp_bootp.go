@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// runBOOTP performs a BOOTREQUEST/BOOTREPLY exchange (RFC 951) for
+// servers that do not speak DHCP
+func (c *DHCPClient) runBOOTP() error {
+	fmt.Println("Starting BOOTP process...")
+
+	requestMsg := c.createBOOTPRequest()
+	fmt.Println("Sending BOOTREQUEST...")
+	if err := c.sendMessage(requestMsg); err != nil {
+		return fmt.Errorf("failed to send BOOTREQUEST: %w", err)
+	}
+
+	fmt.Println("Waiting for BOOTREPLY...")
+	replyMsg, err := c.waitForBOOTPReply(10 * time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to receive BOOTREPLY: %w", err)
+	}
+
+	lease, err := NewBOOTPLease(replyMsg)
+	if err != nil {
+		return fmt.Errorf("failed to parse lease: %w", err)
+	}
+	if err := c.validateLease(replyMsg, lease); err != nil {
+		return fmt.Errorf("rejected BOOTREPLY: %w", err)
+	}
+	c.lease = lease
+
+	fmt.Println("BOOTREPLY received! IP address successfully assigned.")
+	fmt.Printf("Assigned IP: %s\n", lease.IP)
+	fmt.Print(lease.String())
+	return nil
+}
+
+// createBOOTPRequest creates a BOOTREQUEST carrying only RFC 1497 vendor
+// extensions, so Serialize pads it to the 300 byte BOOTP message size
+func (c *DHCPClient) createBOOTPRequest() *DHCPMessage {
+	msg := &DHCPMessage{
+		OpCode:         BootRequest,
+		HardwareType:   c.config.hardwareType(),
+		Hops:           0,
+		TransactionID:  c.transactionID,
+		Seconds:        0,
+		Flags:          0x8000, // Broadcast flag (RFC 1542)
+		ServerHostName: make([]byte, SizeServerHostName),
+		BootFileName:   make([]byte, SizeBootFileName),
+		MagicCookie:    DHCPMagicCookie,
+		Options:        make(map[byte][]byte),
+	}
+
+	msg.HardwareAddressLength, msg.ClientHardwareAddress = chaddrFields(msg.HardwareType, c.macAddr)
+
+	return msg
+}
+
+// waitForBOOTPReply waits for a BOOTREPLY to our transaction. Replies
+// carrying a DHCP message type come from DHCP servers and are skipped.
+func (c *DHCPClient) waitForBOOTPReply(timeout time.Duration) (*DHCPMessage, error) {
+	return c.waitForReply(timeout, func(msg *DHCPMessage) bool {
+		if msg.OpCode != BootReply || msg.TransactionID != c.transactionID {
+			return false
+		}
+		if _, isDHCP := msg.Options[OptionDHCPMessageType]; isDHCP {
+			fmt.Println("Ignoring DHCP reply while in BOOTP mode")
+			return false
+		}
+		return true
+	})
+}
+
+// NewBOOTPLease builds a Lease from a BOOTREPLY. BOOTP assignments do not
+// expire.
+func NewBOOTPLease(msg *DHCPMessage) (*Lease, error) {
+	lease, err := NewLease(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	lease.LeaseTime = InfiniteLeaseTime
+	lease.ServerID = replyServerID(msg)
+	return lease, nil
+}
+
+// replyServerID returns the server identifier of a reply. BOOTREPLYs carry
+// no option 54, so siaddr stands in for it.
+func replyServerID(msg *DHCPMessage) netip.Addr {
+	serverID, _ := optionAddr(msg, OptionServerIdentifier)
+	if _, isDHCP := msg.Options[OptionDHCPMessageType]; !isDHCP && !serverID.IsValid() && msg.NextServerIP != 0 {
+		serverID = uint32ToAddr(msg.NextServerIP)
+	}
+	return serverID
+}
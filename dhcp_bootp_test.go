@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestBOOTPRequestIsPlainBOOTP(t *testing.T) {
+	client := &DHCPClient{
+		macAddr:       []byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55},
+		transactionID: 0x12345678,
+		config:        ClientConfig{BOOTP: true},
+	}
+
+	data, err := client.createBOOTPRequest().Serialize()
+	if err != nil {
+		t.Fatalf("serialize: %v", err)
+	}
+	if len(data) != SizeMinimumBOOTPMessageLength {
+		t.Fatalf("got %d bytes, want %d", len(data), SizeMinimumBOOTPMessageLength)
+	}
+	if binary.BigEndian.Uint32(data[236:240]) != DHCPMagicCookie || data[240] != OptionEnd {
+		t.Fatalf("vendor extensions should hold only the cookie and end option: %v", data[236:244])
+	}
+}
+
+func TestDeserializeWithoutMagicCookie(t *testing.T) {
+	pkt := make([]byte, SizeMinimumBOOTPMessageLength)
+	pkt[0] = BootReply
+	binary.BigEndian.PutUint32(pkt[16:20], 0x0a000005)
+
+	msg, err := Deserialize(pkt)
+	if err != nil {
+		t.Fatalf("deserialize: %v", err)
+	}
+	if msg.MagicCookie != 0 || len(msg.Options) != 0 {
+		t.Fatalf("expected no cookie or options, got 0x%08x %v", msg.MagicCookie, msg.Options)
+	}
+
+	if _, err := Deserialize(pkt[:SizeBOOTPHeader]); err != nil {
+		t.Fatalf("deserialize header-only reply: %v", err)
+	}
+}
+
+func TestBOOTPExchangeAgainstMockServer(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen client: %v", err)
+	}
+	defer conn.Close()
+
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen server: %v", err)
+	}
+	defer server.Close()
+
+	go func() {
+		buf := make([]byte, 1500)
+		_ = server.SetReadDeadline(time.Now().Add(3 * time.Second))
+		n, addr, err := server.ReadFromUDP(buf)
+		if err != nil || n < SizeBOOTPHeader {
+			return
+		}
+
+		reply := make([]byte, SizeMinimumBOOTPMessageLength)
+		copy(reply, buf[:SizeBOOTPHeader])
+		reply[0] = BootReply
+		binary.BigEndian.PutUint32(reply[16:20], 0x0a000005) // yiaddr
+		binary.BigEndian.PutUint32(reply[20:24], 0x0a000001) // siaddr
+		_, _ = server.WriteToUDP(reply, addr)
+	}()
+
+	client := &DHCPClient{
+		macAddr:       []byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55},
+		transactionID: 0x12345678,
+		sendSocket:    conn,
+		receiveSocket: conn,
+		// BOOTREPLYs have no option 54, so siaddr must pass the allow list
+		config: ClientConfig{
+			BOOTP:   true,
+			Servers: ServerPolicy{AllowedServerIDs: []netip.Prefix{netip.MustParsePrefix("10.0.0.1/32")}},
+		},
+	}
+
+	request, err := client.createBOOTPRequest().Serialize()
+	if err != nil {
+		t.Fatalf("serialize: %v", err)
+	}
+	if _, err := conn.WriteToUDP(request, server.LocalAddr().(*net.UDPAddr)); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	reply, err := client.waitForBOOTPReply(3 * time.Second)
+	if err != nil {
+		t.Fatalf("wait for reply: %v", err)
+	}
+
+	lease, err := NewBOOTPLease(reply)
+	if err != nil {
+		t.Fatalf("NewBOOTPLease: %v", err)
+	}
+	if lease.IP != netip.MustParseAddr("10.0.0.5") || lease.ServerID != netip.MustParseAddr("10.0.0.1") {
+		t.Fatalf("unexpected lease %s", lease)
+	}
+	if lease.LeaseTime != InfiniteLeaseTime {
+		t.Fatalf("BOOTP lease should be infinite, got %s", lease.LeaseTime)
+	}
+	if err := client.validateLease(reply, lease); err != nil {
+		t.Fatalf("validateLease: %v", err)
+	}
+
+	// A reply without an address fails validation
+	reply.YourIP = 0
+	if lease, err = NewBOOTPLease(reply); err != nil {
+		t.Fatalf("NewBOOTPLease: %v", err)
+	}
+	if err := client.validateLease(reply, lease); err == nil {
+		t.Fatal("expected a BOOTREPLY without an address to be rejected")
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import "fmt"
+
+// EncodeUserClass encodes user classes as option 77 (RFC 3004 section 2).
+// Each class is preceded by its length.
+func EncodeUserClass(classes [][]byte) ([]byte, error) {
+	var data []byte
+	for _, class := range classes {
+		if len(class) == 0 || len(class) > 255 {
+			return nil, fmt.Errorf("user class must be 1 to 255 bytes, got %d", len(class))
+		}
+		data = append(data, byte(len(class)))
+		data = append(data, class...)
+	}
+	return data, nil
+}
+
+// DecodeUserClass decodes option 77. Values that are not a valid RFC 3004
+// list, such as the plain strings some Microsoft clients send, are
+// returned as a single class.
+func DecodeUserClass(data []byte) ([][]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("user class option is empty")
+	}
+
+	var classes [][]byte
+	for rest := data; len(rest) > 0; {
+		length := int(rest[0])
+		if length == 0 || length >= len(rest) {
+			return [][]byte{data}, nil
+		}
+		classes = append(classes, rest[1:1+length])
+		rest = rest[1+length:]
+	}
+	return classes, nil
+}
+
+// addClassOptions adds the vendor class (60) and user class (77) options
+func (cfg ClientConfig) addClassOptions(options map[byte][]byte) error {
+	if cfg.VendorClass != "" {
+		if cfg.PXE.Enabled {
+			return fmt.Errorf("vendor class cannot be set in PXE mode, which sends its own")
+		}
+		if len(cfg.VendorClass) > 255 {
+			return fmt.Errorf("vendor class must be at most 255 bytes, got %d", len(cfg.VendorClass))
+		}
+		options[OptionVendorClassIdentifier] = []byte(cfg.VendorClass)
+	}
+
+	if len(cfg.UserClasses) > 0 {
+		classes := make([][]byte, len(cfg.UserClasses))
+		for i, class := range cfg.UserClasses {
+			classes[i] = []byte(class)
+		}
+		value, err := EncodeUserClass(classes)
+		if err != nil {
+			return fmt.Errorf("invalid user class: %w", err)
+		}
+		options[OptionUserClass] = value
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUserClassRoundTrip(t *testing.T) {
+	classes := [][]byte{[]byte("kiosk"), []byte("floor-3")}
+
+	data, err := EncodeUserClass(classes)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if want := []byte("\x05kiosk\x07floor-3"); !bytes.Equal(data, want) {
+		t.Fatalf("got %q, want %q", data, want)
+	}
+
+	got, err := DecodeUserClass(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !reflect.DeepEqual(got, classes) {
+		t.Fatalf("got %q, want %q", got, classes)
+	}
+
+	if _, err := EncodeUserClass([][]byte{{}}); err == nil {
+		t.Fatal("expected error for empty class")
+	}
+}
+
+func TestDecodeUserClassPlainString(t *testing.T) {
+	got, err := DecodeUserClass([]byte("workstation"))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 1 || string(got[0]) != "workstation" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestClassOptionsSent(t *testing.T) {
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{
+		VendorClass: "example-os 1.0",
+		UserClasses: []string{"kiosk", "lobby"},
+	})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	msg := client.createDHCPDiscover()
+	if got := string(msg.Options[OptionVendorClassIdentifier]); got != "example-os 1.0" {
+		t.Errorf("vendor class = %q", got)
+	}
+	if got := msg.Options[OptionUserClass]; !bytes.Equal(got, []byte("\x05kiosk\x05lobby")) {
+		t.Errorf("user class = %q", got)
+	}
+	if s := msg.String(); !strings.Contains(s, "'kiosk', 'lobby'") {
+		t.Errorf("String() does not decode the user class:\n%s", s)
+	}
+
+	if _, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{
+		VendorClass: "example-os 1.0",
+		PXE:         PXEConfig{Enabled: true},
+	}); err == nil {
+		t.Fatal("expected error for vendor class in PXE mode")
+	}
+}
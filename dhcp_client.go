@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"time"
@@ -12,6 +13,20 @@ type DHCPClient struct {
 	transactionID uint32
 	sendSocket    *net.UDPConn
 	receiveSocket *net.UDPConn
+	lease         *Lease
+	config        ClientConfig
+	clientOptions map[byte][]byte
+	auth          *authenticator
+	state         ClientState
+	forceRenew    forceRenewState
+	// maxMessageSize is the option 57 value, which sizes the receive buffer
+	maxMessageSize int
+	// requestList is the option 55 value sent in every message
+	requestList []byte
+	// captivePortal and proxyAutoConfig are fetched from the URIs in the
+	// lease
+	captivePortal   *CaptivePortalStatus
+	proxyAutoConfig string
 }
 
 // NewDHCPClient creates a new DHCP client
@@ -22,6 +37,51 @@ func NewDHCPClient(macAddr []byte) *DHCPClient {
 	}
 }
 
+// NewDHCPClientWithConfig creates a new DHCP client with optional settings.
+// macAddr may be nil when config.MAC generates the address.
+func NewDHCPClientWithConfig(macAddr []byte, config ClientConfig) (*DHCPClient, error) {
+	if config.Anonymous {
+		var err error
+		if config, err = config.anonymize(); err != nil {
+			return nil, err
+		}
+	}
+	macAddr, err := config.hardwareAddress(macAddr)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateHardwareAddress(config.hardwareType(), macAddr); err != nil {
+		return nil, fmt.Errorf("invalid hardware address: %w", err)
+	}
+
+	clientOptions, err := config.buildClientOptions(macAddr)
+	if err != nil {
+		return nil, err
+	}
+	requestList, err := config.parameterRequestList()
+	if err != nil {
+		return nil, err
+	}
+	if config.Authentication.Enabled && config.Authentication.Keys == nil {
+		return nil, fmt.Errorf("authentication requires a key store")
+	}
+	if config.Authentication.Required && config.ForceRenew {
+		// The RFC 6704 nonce arrives in a DHCPACK that carries no RFC 3118
+		// HMAC, so trusting it would let any server bypass authentication
+		return nil, fmt.Errorf("FORCERENEW nonce authentication cannot be combined with required authentication")
+	}
+
+	client := NewDHCPClient(macAddr)
+	client.config = config
+	client.clientOptions = clientOptions
+	client.maxMessageSize, _ = config.maxMessageSize() // Checked by buildClientOptions
+	client.requestList = requestList
+	if config.Authentication.Enabled {
+		client.auth = newAuthenticator(config.Authentication)
+	}
+	return client, nil
+}
+
 // Start initiates the DHCP process
 func (c *DHCPClient) Start() error {
 	// Create sockets
@@ -30,25 +90,65 @@ func (c *DHCPClient) Start() error {
 	}
 	defer c.cleanup()
 
+	if c.config.BOOTP {
+		return c.runBOOTP()
+	}
+
+	for {
+		err := c.runDHCP()
+		switch {
+		case errors.Is(err, errIPv6OnlyPreferred):
+			// Resume DHCPv4 once V6ONLY_WAIT has passed (RFC 8925 section 3.2)
+			time.Sleep(c.lease.V6OnlyWait)
+		case errors.Is(err, errLeaseLost):
+			fmt.Println("Lease lost, restarting discovery")
+			c.lease = nil
+		default:
+			return err
+		}
+		c.setState(StateInit)
+	}
+}
+
+// runDHCP performs one DISCOVER/OFFER/REQUEST/ACK exchange
+func (c *DHCPClient) runDHCP() error {
 	fmt.Println("Starting DHCP process...")
 
+	// Anonymous exchanges must not share an xid. secs stays zero in every
+	// mode, so it carries no timing information either.
+	if c.config.Anonymous {
+		c.transactionID = randomTransactionID()
+	}
+
 	// Step 1: Send DHCPDISCOVER
+	c.setState(StateSelecting)
 	discoverMsg := c.createDHCPDiscover()
 	fmt.Println("Sending DHCPDISCOVER...")
 	if err := c.sendMessage(discoverMsg); err != nil {
 		return fmt.Errorf("failed to send DHCPDISCOVER: %w", err)
 	}
 
-	// Step 2: Wait for DHCPOFFER
+	// Step 2: Wait for DHCPOFFER, or a DHCPACK if the server honoured
+	// Rapid Commit
 	fmt.Println("Waiting for DHCPOFFER...")
-	offerMsg, err := c.waitForMessage(DHCPOffer, 10*time.Second)
+	offerMsg, err := c.waitForOffer(10 * time.Second)
 	if err != nil {
 		return fmt.Errorf("failed to receive DHCPOFFER: %w", err)
 	}
 
+	if c.v6OnlyPreferred(offerMsg) {
+		return c.enterV6OnlyWait(offerMsg)
+	}
+
+	if isRapidCommitAck(offerMsg) {
+		fmt.Println("Received rapid commit DHCPACK, skipping DHCPREQUEST")
+		return c.bind(offerMsg)
+	}
+
 	fmt.Printf("Received DHCPOFFER:\n%s", offerMsg.String())
 
 	// Step 3: Send DHCPREQUEST
+	c.setState(StateRequesting)
 	requestMsg := c.createDHCPRequest(offerMsg)
 	fmt.Println("Sending DHCPREQUEST...")
 	if err := c.sendMessage(requestMsg); err != nil {
@@ -66,6 +166,44 @@ func (c *DHCPClient) Start() error {
 		}
 	}
 
+	return c.bind(responseMsg)
+}
+
+// bind handles the response to the initial exchange and, when the client
+// accepts FORCERENEW, stays bound to maintain the lease
+func (c *DHCPClient) bind(responseMsg *DHCPMessage) error {
+	if err := c.handleResponse(responseMsg); err != nil {
+		return err
+	}
+	if err := c.configure(); err != nil {
+		return err
+	}
+	if c.config.PXE.Enabled {
+		if err := c.bootPXE(responseMsg); err != nil {
+			return err
+		}
+	}
+	if c.config.ForceRenew {
+		return c.runBound()
+	}
+	return nil
+}
+
+// configure applies the settings of a new or renewed lease
+func (c *DHCPClient) configure() error {
+	if err := c.applyMTU(); err != nil {
+		return err
+	}
+	if err := c.applyTimeConfig(); err != nil {
+		return err
+	}
+	c.fetchWebInfo()
+	c.provision()
+	return nil
+}
+
+// handleResponse processes the DHCPACK or DHCPNAK that ends an exchange
+func (c *DHCPClient) handleResponse(responseMsg *DHCPMessage) error {
 	fmt.Printf("Received response:\n%s", responseMsg.String())
 
 	// Check if we got ACK or NAK
@@ -73,7 +211,18 @@ func (c *DHCPClient) Start() error {
 		switch msgType[0] {
 		case DHCPAck:
 			fmt.Println("DHCPACK received! IP address successfully assigned.")
-			fmt.Printf("Assigned IP: %s\n", responseMsg.ipToString(responseMsg.YourIP))
+			lease, err := c.newLease(responseMsg)
+			if err != nil {
+				return fmt.Errorf("failed to parse lease: %w", err)
+			}
+			if err := c.validateLease(responseMsg, lease); err != nil {
+				return fmt.Errorf("rejected DHCPACK: %w", err)
+			}
+			c.lease = lease
+			c.setState(StateBound)
+			c.learnForceRenewNonce(responseMsg)
+			fmt.Printf("Assigned IP: %s\n", lease.IP)
+			fmt.Print(lease.String())
 			return nil
 		case DHCPNak:
 			fmt.Println("DHCPNAK received! IP address assignment failed.")
@@ -86,6 +235,11 @@ func (c *DHCPClient) Start() error {
 	return fmt.Errorf("no message type found in response")
 }
 
+// Lease returns the lease obtained by the last successful Start, or nil
+func (c *DHCPClient) Lease() *Lease {
+	return c.lease
+}
+
 // createSockets creates the UDP sockets for sending and receiving
 func (c *DHCPClient) createSockets() error {
 	var err error
@@ -122,27 +276,30 @@ func (c *DHCPClient) cleanup() {
 // createDHCPDiscover creates a DHCPDISCOVER message
 func (c *DHCPClient) createDHCPDiscover() *DHCPMessage {
 	msg := &DHCPMessage{
-		OpCode:                1, // Boot request
-		HardwareType:          1, // Ethernet
-		HardwareAddressLength: 6, // MAC address length
-		Hops:                  0,
-		TransactionID:         c.transactionID,
-		Seconds:               0,
-		Flags:                 0x8000, // Broadcast flag
-		ClientHardwareAddress: make([]byte, 16),
-		ServerHostName:        make([]byte, 64),
-		BootFileName:          make([]byte, 128),
-		MagicCookie:           0x63825363,
-		Options:               make(map[byte][]byte),
-	}
-
-	// Copy MAC address to ClientHardwareAddress (first 6 bytes)
-	copy(msg.ClientHardwareAddress, c.macAddr)
+		OpCode:         1, // Boot request
+		HardwareType:   c.config.hardwareType(),
+		Hops:           0,
+		TransactionID:  c.transactionID,
+		Seconds:        0,
+		Flags:          0x8000, // Broadcast flag
+		ServerHostName: make([]byte, 64),
+		BootFileName:   make([]byte, 128),
+		MagicCookie:    0x63825363,
+		Options:        make(map[byte][]byte),
+	}
+
+	// Copy the hardware address to ClientHardwareAddress and set hlen
+	msg.HardwareAddressLength, msg.ClientHardwareAddress = chaddrFields(msg.HardwareType, c.macAddr)
 
 	// Add required DHCP options
 	msg.Options[OptionDHCPMessageType] = []byte{DHCPDiscover}
-	msg.Options[OptionClientIdentifier] = append([]byte{1}, c.macAddr...) // Type 1 (Ethernet) + MAC
-	msg.Options[OptionParameterRequestList] = []byte{1, 3, 6, 15, 31, 33, 43, 44, 46, 47, 119, 121, 249, 252}
+	msg.Options[OptionClientIdentifier] = append([]byte{msg.HardwareType}, c.macAddr...) // Hardware type + address
+	c.addClientOptions(msg)
+
+	// Ask for the two-message exchange (RFC 4039)
+	if c.config.RapidCommit {
+		msg.Options[OptionRapidCommit] = []byte{}
+	}
 
 	return msg
 }
@@ -150,26 +307,24 @@ func (c *DHCPClient) createDHCPDiscover() *DHCPMessage {
 // createDHCPRequest creates a DHCPREQUEST message based on the received offer
 func (c *DHCPClient) createDHCPRequest(offerMsg *DHCPMessage) *DHCPMessage {
 	msg := &DHCPMessage{
-		OpCode:                1, // Boot request
-		HardwareType:          1, // Ethernet
-		HardwareAddressLength: 6, // MAC address length
-		Hops:                  0,
-		TransactionID:         c.transactionID, // Same transaction ID
-		Seconds:               0,
-		Flags:                 0x8000, // Broadcast flag
-		ClientHardwareAddress: make([]byte, 16),
-		ServerHostName:        make([]byte, 64),
-		BootFileName:          make([]byte, 128),
-		MagicCookie:           0x63825363,
-		Options:               make(map[byte][]byte),
-	}
-
-	// Copy MAC address to ClientHardwareAddress (first 6 bytes)
-	copy(msg.ClientHardwareAddress, c.macAddr)
+		OpCode:         1, // Boot request
+		HardwareType:   c.config.hardwareType(),
+		Hops:           0,
+		TransactionID:  c.transactionID, // Same transaction ID
+		Seconds:        0,
+		Flags:          0x8000, // Broadcast flag
+		ServerHostName: make([]byte, 64),
+		BootFileName:   make([]byte, 128),
+		MagicCookie:    0x63825363,
+		Options:        make(map[byte][]byte),
+	}
+
+	// Copy the hardware address to ClientHardwareAddress and set hlen
+	msg.HardwareAddressLength, msg.ClientHardwareAddress = chaddrFields(msg.HardwareType, c.macAddr)
 
 	// Add required DHCP options
 	msg.Options[OptionDHCPMessageType] = []byte{DHCPRequest}
-	msg.Options[OptionClientIdentifier] = append([]byte{1}, c.macAddr...) // Type 1 (Ethernet) + MAC
+	msg.Options[OptionClientIdentifier] = append([]byte{msg.HardwareType}, c.macAddr...) // Hardware type + address
 
 	// Request the offered IP address
 	if offeredIP, exists := offerMsg.Options[OptionRequestedIPAddress]; exists {
@@ -197,12 +352,23 @@ func (c *DHCPClient) createDHCPRequest(offerMsg *DHCPMessage) *DHCPMessage {
 		}
 	}
 
-	// Request the same parameters as in DISCOVER
-	msg.Options[OptionParameterRequestList] = []byte{1, 3, 6, 15, 31, 33, 43, 44, 46, 47, 119, 121, 249, 252}
+	c.addClientOptions(msg)
 
 	return msg
 }
 
+// addClientOptions adds the parameter request list and the options built
+// from the client configuration
+func (c *DHCPClient) addClientOptions(msg *DHCPMessage) {
+	msg.Options[OptionParameterRequestList] = c.parameterRequestList()
+	for code, value := range c.clientOptions {
+		msg.Options[code] = value
+	}
+	if c.auth != nil {
+		msg.Options[OptionAuthentication] = c.auth.option()
+	}
+}
+
 // sendMessage sends a DHCP message
 func (c *DHCPClient) sendMessage(msg *DHCPMessage) error {
 	data, err := msg.Serialize()
@@ -210,6 +376,12 @@ func (c *DHCPClient) sendMessage(msg *DHCPMessage) error {
 		return fmt.Errorf("failed to serialize message: %w", err)
 	}
 
+	if c.auth != nil {
+		if err := c.auth.sign(data); err != nil {
+			return fmt.Errorf("failed to authenticate message: %w", err)
+		}
+	}
+
 	_, err = c.sendSocket.Write(data)
 	if err != nil {
 		return fmt.Errorf("failed to send message: %w", err)
@@ -220,13 +392,85 @@ func (c *DHCPClient) sendMessage(msg *DHCPMessage) error {
 
 // waitForMessage waits for a specific DHCP message type
 func (c *DHCPClient) waitForMessage(expectedType byte, timeout time.Duration) (*DHCPMessage, error) {
+	return c.waitForReply(timeout, func(msg *DHCPMessage) bool {
+		// Check if this is the expected message type
+		if msgType, exists := msg.Options[OptionDHCPMessageType]; exists && len(msgType) > 0 {
+			if msgType[0] == expectedType {
+				return true
+			}
+
+			// If not the expected type, continue waiting
+			fmt.Printf("Received message type %d, waiting for %d\n",
+				msg.Options[OptionDHCPMessageType][0], expectedType)
+		}
+		return false
+	})
+}
+
+// waitForOffer waits for a DHCPOFFER that passes validation. With Rapid
+// Commit enabled a DHCPACK carrying option 80 is accepted as well (RFC
+// 4039); servers that ignore Rapid Commit still answer with a normal offer.
+func (c *DHCPClient) waitForOffer(timeout time.Duration) (*DHCPMessage, error) {
+	return c.waitForReply(timeout, func(msg *DHCPMessage) bool {
+		msgType, exists := msg.Options[OptionDHCPMessageType]
+		if !exists || len(msgType) == 0 {
+			return false
+		}
+		if msgType[0] == DHCPOffer {
+			if err := c.validateReply(msg); err != nil {
+				fmt.Printf("Ignoring DHCPOFFER: %v\n", err)
+				return false
+			}
+			return true
+		}
+		if c.config.RapidCommit && isRapidCommitAck(msg) {
+			return true
+		}
+
+		fmt.Printf("Received message type %d, waiting for %d\n", msgType[0], DHCPOffer)
+		return false
+	})
+}
+
+// isRapidCommitAck reports whether msg is a DHCPACK sent in reply to a
+// Rapid Commit DHCPDISCOVER
+func isRapidCommitAck(msg *DHCPMessage) bool {
+	msgType := msg.Options[OptionDHCPMessageType]
+	_, rapidCommit := msg.Options[OptionRapidCommit]
+	return len(msgType) > 0 && msgType[0] == DHCPAck && rapidCommit
+}
+
+// waitForReply reads messages until accept returns true or the timeout
+// expires. Messages from servers the server policy rejects are dropped.
+func (c *DHCPClient) waitForReply(timeout time.Duration, accept func(msg *DHCPMessage) bool) (*DHCPMessage, error) {
+	var reply *DHCPMessage
+	err := c.receiveReplies(timeout, func(msg *DHCPMessage, from *net.UDPAddr) bool {
+		if err := c.checkServer(msg, from); err != nil {
+			fmt.Printf("Dropping message from rejected server: %v\n", err)
+			return false
+		}
+		if accept(msg) {
+			reply = msg
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// receiveReplies reads messages until handle returns true or the timeout
+// expires. Messages that fail authentication are dropped.
+func (c *DHCPClient) receiveReplies(timeout time.Duration, handle func(msg *DHCPMessage, from *net.UDPAddr) bool) error {
 	c.receiveSocket.SetReadDeadline(time.Now().Add(timeout))
 
-	buf := make([]byte, 1024)
+	buf := make([]byte, c.receiveBufferSize())
 	for {
 		n, addr, err := c.receiveSocket.ReadFromUDP(buf)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read from socket: %w", err)
+			return fmt.Errorf("failed to read from socket: %w", err)
 		}
 
 		fmt.Printf("Received %d bytes from %s\n", n, addr.String())
@@ -239,16 +483,15 @@ func (c *DHCPClient) waitForMessage(expectedType byte, timeout time.Duration) (*
 
 		fmt.Printf("Received message:\n%s", msg.String())
 
-		// Check if this is the expected message type
-		if msgType, exists := msg.Options[OptionDHCPMessageType]; exists && len(msgType) > 0 {
-			if msgType[0] == expectedType {
-				return msg, nil
+		if c.auth != nil {
+			if err := c.auth.verify(msg); err != nil {
+				fmt.Printf("Dropping message that failed authentication: %v\n", err)
+				continue
 			}
-
-			// If not the expected type, continue waiting
-			fmt.Printf("Received message type %d, waiting for %d\n",
-				msg.Options[OptionDHCPMessageType][0], expectedType)
 		}
 
+		if handle(msg, addr) {
+			return nil
+		}
 	}
 }
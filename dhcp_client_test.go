@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// replyToDiscover starts a mock server that answers one DHCPDISCOVER by
+// letting reply fill in the response
+func replyToDiscover(t *testing.T, reply func(discover *DHCPMessage) *DHCPMessage) *DHCPClient {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		_ = server.SetReadDeadline(time.Now().Add(3 * time.Second))
+		n, addr, err := server.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		discover, err := Deserialize(buf[:n])
+		if err != nil {
+			t.Errorf("deserialize discover: %v", err)
+			return
+		}
+
+		msg := reply(discover)
+		msg.OpCode = BootReply
+		msg.TransactionID = discover.TransactionID
+		msg.YourIP = 0x0a000005
+		msg.ClientHardwareAddress = discover.ClientHardwareAddress
+		msg.ServerHostName = make([]byte, SizeServerHostName)
+		msg.BootFileName = make([]byte, SizeBootFileName)
+		msg.MagicCookie = DHCPMagicCookie
+		msg.Options[OptionServerIdentifier] = []byte{10, 0, 0, 1}
+		msg.Options[OptionIPAddressLeaseTime] = []byte{0, 0, 0x0e, 0x10}
+
+		data, err := msg.Serialize()
+		if err != nil {
+			t.Errorf("serialize reply: %v", err)
+			return
+		}
+		_, _ = server.WriteToUDP(data, addr)
+	}()
+
+	client := &DHCPClient{
+		macAddr:       []byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55},
+		transactionID: 0x12345678,
+		sendSocket:    conn,
+		receiveSocket: conn,
+		config:        ClientConfig{RapidCommit: true},
+	}
+
+	data, err := client.createDHCPDiscover().Serialize()
+	if err != nil {
+		t.Fatalf("serialize discover: %v", err)
+	}
+	if _, err := conn.WriteToUDP(data, server.LocalAddr().(*net.UDPAddr)); err != nil {
+		t.Fatalf("send discover: %v", err)
+	}
+
+	return client
+}
+
+func TestRapidCommitAck(t *testing.T) {
+	client := replyToDiscover(t, func(discover *DHCPMessage) *DHCPMessage {
+		if _, exists := discover.Options[OptionRapidCommit]; !exists {
+			t.Errorf("DHCPDISCOVER is missing the Rapid Commit option")
+		}
+		return &DHCPMessage{Options: map[byte][]byte{
+			OptionDHCPMessageType: {DHCPAck},
+			OptionRapidCommit:     {},
+		}}
+	})
+
+	msg, err := client.waitForOffer(3 * time.Second)
+	if err != nil {
+		t.Fatalf("waitForOffer: %v", err)
+	}
+	if !isRapidCommitAck(msg) {
+		t.Fatalf("expected a rapid commit DHCPACK, got %s", msg)
+	}
+	if err := client.handleResponse(msg); err != nil {
+		t.Fatalf("handleResponse: %v", err)
+	}
+	if client.Lease() == nil || client.Lease().IP.String() != "10.0.0.5" {
+		t.Fatalf("unexpected lease %v", client.Lease())
+	}
+}
+
+func TestRapidCommitIgnoredByServer(t *testing.T) {
+	client := replyToDiscover(t, func(*DHCPMessage) *DHCPMessage {
+		return &DHCPMessage{Options: map[byte][]byte{
+			OptionDHCPMessageType: {DHCPOffer},
+		}}
+	})
+
+	msg, err := client.waitForOffer(3 * time.Second)
+	if err != nil {
+		t.Fatalf("waitForOffer: %v", err)
+	}
+	if isRapidCommitAck(msg) || msg.Options[OptionDHCPMessageType][0] != DHCPOffer {
+		t.Fatalf("expected a normal DHCPOFFER, got %s", msg)
+	}
+}
+
+func TestDiscoverWithoutRapidCommit(t *testing.T) {
+	client := &DHCPClient{macAddr: []byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}}
+	if _, exists := client.createDHCPDiscover().Options[OptionRapidCommit]; exists {
+		t.Fatal("Rapid Commit option sent without being enabled")
+	}
+}
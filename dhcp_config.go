@@ -0,0 +1,359 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ClientIDMode selects how the client identifier (option 61) is built
+type ClientIDMode uint8
+
+// Client identifier modes
+const (
+	ClientIDMAC    ClientIDMode = iota // Hardware type 1 followed by the MAC address
+	ClientIDDUID                       // RFC 4361 IAID and DUID
+	ClientIDOpaque                     // Caller-supplied value
+)
+
+// ClientIdentifierConfig configures the client identifier (option 61)
+type ClientIdentifierConfig struct {
+	Mode ClientIDMode
+
+	// DUIDType selects the DUID to generate in ClientIDDUID mode. Zero
+	// means DUID-LLT.
+	DUIDType uint16
+	// DUIDPath is where the generated DUID is persisted. When empty the
+	// DUID is regenerated on every start.
+	DUIDPath string
+	// IAID identifies the interface. Zero derives it from the last four
+	// bytes of the MAC address and, when DUIDPath is set, stores it in
+	// DUIDPath with an ".iaid" suffix.
+	IAID uint32
+	// EnterpriseNumber and EnterpriseID are used for DUID-EN
+	EnterpriseNumber uint32
+	EnterpriseID     []byte
+	// UUID is used for DUID-UUID
+	UUID [16]byte
+
+	// Opaque is the complete option 61 value in ClientIDOpaque mode
+	Opaque []byte
+}
+
+// NameOption selects which name options the client sends
+type NameOption uint8
+
+// Name option choices
+const (
+	NameOptionAuto     NameOption = iota // Client FQDN if FQDN is set, otherwise hostname
+	NameOptionHostname                   // Hostname (12) only
+	NameOptionFQDN                       // Client FQDN (81) only
+	NameOptionNone                       // Neither
+)
+
+// FQDNUpdate selects who performs DNS updates for the client FQDN
+type FQDNUpdate uint8
+
+// DNS update choices for option 81
+const (
+	FQDNUpdateServer FQDNUpdate = iota // Server updates A and PTR records
+	FQDNUpdateClient                   // Client updates the A record itself
+	FQDNUpdateNone                     // No DNS updates
+)
+
+// AuthConfig configures DHCP authentication (option 90, RFC 3118)
+type AuthConfig struct {
+	// Enabled requests delayed authentication with HMAC-MD5
+	Enabled bool
+	// Required drops responses that are not authenticated, so only servers
+	// holding a shared secret can configure the client
+	Required bool
+	// Keys holds the shared secrets, keyed by secret ID
+	Keys KeyStore
+}
+
+// ClientConfig holds optional settings for a DHCPClient. The zero value
+// gives the default behaviour.
+type ClientConfig struct {
+	// VendorClass is sent as option 60 so the server can identify the
+	// client's vendor and configuration
+	VendorClass string
+	// UserClasses are sent as option 77 (RFC 3004) so the server can place
+	// the client in user-defined classes, e.g. "kiosk"
+	UserClasses []string
+
+	// VIVendorClasses identify the device to the server through option 124
+	// (RFC 3925), e.g. for zero-touch provisioning
+	VIVendorClasses []VIVendorClass
+
+	// Hostname is sent as option 12
+	Hostname string
+	// FQDN is sent as option 81 (RFC 4702) so DDNS-integrated servers can
+	// register the client
+	FQDN string
+	// FQDNUpdate tells the server who updates DNS for FQDN
+	FQDNUpdate FQDNUpdate
+	// SendName chooses between options 12 and 81. RFC 4702 advises against
+	// sending both.
+	SendName NameOption
+
+	// ClientIdentifier chooses the client identifier sent in option 61
+	ClientIdentifier ClientIdentifierConfig
+
+	// Authentication configures RFC 3118 delayed authentication
+	Authentication AuthConfig
+
+	// ForceRenew advertises RFC 6704 nonce authentication (option 145) and
+	// keeps the client bound after the exchange, renewing at T1 or when an
+	// authenticated DHCPFORCERENEW arrives. It cannot be combined with
+	// Authentication.Required.
+	ForceRenew bool
+
+	// IPv6OnlyPreferred requests option 108 (RFC 8925). When the server
+	// returns it the client leaves IPv4 unconfigured for V6ONLY_WAIT and
+	// then restarts DHCPv4.
+	IPv6OnlyPreferred bool
+
+	// OnStateChange, if set, is called whenever the client changes state
+	OnStateChange func(from, to ClientState)
+
+	// FetchCaptivePortal requests option 114 and queries the captive
+	// portal API (RFC 8908) after binding
+	FetchCaptivePortal bool
+	// FetchProxyAutoConfig downloads the PAC file named by option 252
+	// after binding
+	FetchProxyAutoConfig bool
+	// HTTPClient is used for those requests. Nil uses a default client.
+	HTTPClient *http.Client
+
+	// Interface is the network interface the client runs on. Its MTU sizes
+	// the maximum message size (option 57) unless MTU is set.
+	Interface string
+	// MTU overrides the interface MTU
+	MTU int
+	// Applier applies lease settings such as the MTU (option 26) to the
+	// system. Nil leaves the system unchanged.
+	Applier ConfigApplier
+
+	// Time selects where NTP configuration from the lease is written
+	Time TimeConfig
+
+	// Provisioning downloads zero-touch provisioning artifacts
+	Provisioning ProvisioningConfig
+
+	// PXE configures PXE network boot client mode
+	PXE PXEConfig
+
+	// RequestProfile is the base set of options requested in option 55.
+	// Empty means RequestProfileDesktop.
+	RequestProfile RequestProfile
+	// RequestOptions are requested in addition to the profile, each given
+	// by code ("42") or registry name ("ntp-servers")
+	RequestOptions []string
+	// ExtraOptions are sent verbatim in every message, replacing options
+	// built from other settings
+	ExtraOptions map[byte][]byte
+
+	// Servers restricts the DHCP servers whose replies are accepted, to
+	// guard against rogue servers
+	Servers ServerPolicy
+
+	// Validation selects how offers and leases that fail the sanity checks
+	// are handled
+	Validation ValidationPolicy
+
+	// MAC selects a generated hardware address for chaddr and the client
+	// identifier instead of the one the client was created with
+	MAC MACConfig
+
+	// Anonymous enables the RFC 7844 anonymity profile: the client uses a
+	// generated hardware address and client identifier (random unless MAC
+	// selects a stable one), sends no name or class options, requests only
+	// the anonymous profile and uses a new xid for every exchange. It
+	// overrides the settings above that would identify the client.
+	Anonymous bool
+
+	// RapidCommit requests the two-message DISCOVER/ACK exchange (RFC 4039)
+	RapidCommit bool
+
+	// BOOTP runs a plain BOOTREQUEST/BOOTREPLY exchange (RFC 951) for
+	// legacy servers instead of DHCP
+	BOOTP bool
+
+	// HardwareType is the ARP hardware type of the interface. Zero means
+	// Ethernet.
+	HardwareType uint8
+}
+
+// hardwareType returns the configured hardware type
+func (cfg ClientConfig) hardwareType() uint8 {
+	if cfg.HardwareType == 0 {
+		return HardwareTypeEthernet
+	}
+	return cfg.HardwareType
+}
+
+// buildClientOptions encodes the options the configuration adds to every
+// outgoing message
+func (cfg ClientConfig) buildClientOptions(macAddr []byte) (map[byte][]byte, error) {
+	options := make(map[byte][]byte)
+
+	if err := cfg.addMaxMessageSize(options); err != nil {
+		return nil, err
+	}
+
+	clientID, err := cfg.ClientIdentifier.build(cfg.hardwareType(), macAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client identifier: %w", err)
+	}
+	options[OptionClientIdentifier] = clientID
+
+	if err := cfg.addClassOptions(options); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.VIVendorClasses) > 0 {
+		value, err := EncodeVIVendorClass(cfg.VIVendorClasses)
+		if err != nil {
+			return nil, fmt.Errorf("invalid V-I vendor class: %w", err)
+		}
+		options[OptionVIVendorClass] = value
+	}
+
+	if cfg.PXE.Enabled {
+		cfg.PXE.addOptions(options)
+	}
+
+	if cfg.ForceRenew {
+		options[OptionForceRenewNonceCapable] = []byte{AuthAlgorithmHMACMD5}
+	}
+
+	if err := cfg.addNameOptions(options); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.addExtraOptions(options); err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// addNameOptions adds the hostname or client FQDN option selected by
+// SendName
+func (cfg ClientConfig) addNameOptions(options map[byte][]byte) error {
+	hostname := cfg.Hostname
+	if hostname == "" {
+		hostname, _, _ = strings.Cut(cfg.FQDN, ".")
+	}
+	fqdn := cfg.FQDN
+	if fqdn == "" {
+		fqdn = cfg.Hostname
+	}
+
+	sendName := cfg.SendName
+	if sendName == NameOptionAuto {
+		sendName = NameOptionHostname
+		if cfg.FQDN != "" {
+			sendName = NameOptionFQDN
+		}
+	}
+
+	switch sendName {
+	case NameOptionHostname:
+		if hostname != "" {
+			options[OptionHostName] = []byte(hostname)
+		}
+	case NameOptionFQDN:
+		if fqdn == "" {
+			return nil
+		}
+		option := &ClientFQDN{Name: fqdn}
+		switch cfg.FQDNUpdate {
+		case FQDNUpdateServer:
+			option.Flags = FQDNFlagServerUpdate
+		case FQDNUpdateNone:
+			option.Flags = FQDNFlagNoUpdate
+		}
+		value, err := option.Encode()
+		if err != nil {
+			return err
+		}
+		options[OptionClientFQDN] = value
+	}
+
+	return nil
+}
+
+// build encodes the option 61 value
+func (cfg ClientIdentifierConfig) build(htype uint8, macAddr []byte) ([]byte, error) {
+	switch cfg.Mode {
+	case ClientIDMAC:
+		if LookupHardwareType(htype).RequiresClientID {
+			cfg.Mode = ClientIDDUID
+			if cfg.DUIDType == 0 {
+				cfg.DUIDType = DUIDTypeLL
+			}
+			return cfg.build(htype, macAddr)
+		}
+		return append([]byte{htype}, macAddr...), nil // Hardware type + address
+	case ClientIDOpaque:
+		if len(cfg.Opaque) < 2 {
+			return nil, fmt.Errorf("opaque client identifier must be at least 2 bytes, got %d", len(cfg.Opaque))
+		}
+		return cfg.Opaque, nil
+	case ClientIDDUID:
+		iaid := cfg.IAID
+		if iaid == 0 && len(macAddr) >= 4 {
+			iaid = binary.BigEndian.Uint32(macAddr[len(macAddr)-4:])
+		}
+
+		create := func() (DUID, error) { return cfg.newDUID(htype, macAddr) }
+		var duid DUID
+		var err error
+		if cfg.DUIDPath != "" {
+			duid, err = LoadOrCreateDUID(cfg.DUIDPath, create)
+		} else {
+			duid, err = create()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// A MAC-derived IAID is stored beside the DUID, so the identifier
+		// stays the same when the MAC is generated or the NIC replaced
+		if cfg.IAID == 0 && cfg.DUIDPath != "" {
+			if iaid, err = LoadOrCreateIAID(cfg.DUIDPath+".iaid", iaid); err != nil {
+				return nil, err
+			}
+		}
+
+		return EncodeDUIDClientID(iaid, duid)
+	default:
+		return nil, fmt.Errorf("unknown client identifier mode %d", cfg.Mode)
+	}
+}
+
+// newDUID generates a DUID of the configured type
+func (cfg ClientIdentifierConfig) newDUID(htype uint8, macAddr []byte) (DUID, error) {
+	switch cfg.DUIDType {
+	case 0, DUIDTypeLLT:
+		return NewDUIDLLT(uint16(htype), time.Now(), macAddr), nil
+	case DUIDTypeEN:
+		if len(cfg.EnterpriseID) == 0 {
+			return nil, fmt.Errorf("DUID-EN requires an enterprise identifier")
+		}
+		return NewDUIDEN(cfg.EnterpriseNumber, cfg.EnterpriseID), nil
+	case DUIDTypeLL:
+		return NewDUIDLL(uint16(htype), macAddr), nil
+	case DUIDTypeUUID:
+		if cfg.UUID == [16]byte{} {
+			return nil, fmt.Errorf("DUID-UUID requires a UUID")
+		}
+		return NewDUIDUUID(cfg.UUID), nil
+	default:
+		return nil, fmt.Errorf("unsupported DUID type %d", cfg.DUIDType)
+	}
+}
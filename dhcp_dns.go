@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DNS name limits from RFC 1035
+const (
+	maxDNSLabelLength = 63
+	maxDNSNameLength  = 255
+	dnsPointerMask    = 0xc0
+	maxDNSPointer     = 0x3fff
+)
+
+// splitDNSName validates a domain name and returns its labels. A trailing
+// dot is accepted and the root name ("" or ".") has no labels.
+func splitDNSName(name string) ([]string, error) {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return nil, nil
+	}
+
+	labels := strings.Split(name, ".")
+	length := 1
+	for _, label := range labels {
+		if label == "" {
+			return nil, fmt.Errorf("domain name %q has an empty label", name)
+		}
+		if len(label) > maxDNSLabelLength {
+			return nil, fmt.Errorf("label %q in %q exceeds %d bytes", label, name, maxDNSLabelLength)
+		}
+		length += len(label) + 1
+	}
+
+	if length > maxDNSNameLength {
+		return nil, fmt.Errorf("domain name %q exceeds %d bytes", name, maxDNSNameLength)
+	}
+
+	return labels, nil
+}
+
+// encodeDNSName encodes a domain name as an uncompressed sequence of
+// length-prefixed labels terminated by the root label, as required by
+// options such as 81 (RFC 4702).
+func encodeDNSName(name string) ([]byte, error) {
+	labels, err := splitDNSName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	for _, label := range labels {
+		data = append(data, byte(len(label)))
+		data = append(data, label...)
+	}
+	return append(data, 0), nil
+}
+
+// decodeDNSName decodes the domain name starting at offset in data,
+// following compression pointers. It returns the name without a trailing
+// dot and the offset just past the name in data. Each pointer must refer to
+// data before the previous jump target, which rules out pointer loops.
+func decodeDNSName(data []byte, offset int) (string, int, error) {
+	var labels []string
+	next := -1
+	length := 1
+	pos := offset
+	limit := offset
+
+	for {
+		if pos >= len(data) {
+			return "", 0, fmt.Errorf("truncated domain name at offset %d", offset)
+		}
+
+		b := data[pos]
+		switch {
+		case b == 0:
+			if next < 0 {
+				next = pos + 1
+			}
+			return strings.Join(labels, "."), next, nil
+
+		case b&dnsPointerMask == dnsPointerMask:
+			if pos+1 >= len(data) {
+				return "", 0, fmt.Errorf("truncated compression pointer at offset %d", pos)
+			}
+			target := int(b&^dnsPointerMask)<<8 | int(data[pos+1])
+			if target >= limit {
+				return "", 0, fmt.Errorf("compression pointer at offset %d does not point backwards", pos)
+			}
+			if next < 0 {
+				next = pos + 2
+			}
+			pos = target
+			limit = target
+
+		case b&dnsPointerMask != 0:
+			return "", 0, fmt.Errorf("unsupported label type 0x%02x at offset %d", b&dnsPointerMask, pos)
+
+		default:
+			end := pos + 1 + int(b)
+			if end > len(data) {
+				return "", 0, fmt.Errorf("truncated label at offset %d", pos)
+			}
+			length += int(b) + 1
+			if length > maxDNSNameLength {
+				return "", 0, fmt.Errorf("domain name at offset %d exceeds %d bytes", offset, maxDNSNameLength)
+			}
+			labels = append(labels, string(data[pos+1:end]))
+			pos = end
+		}
+	}
+}
+
+// DecodeDomainSearch decodes an RFC 3397 domain search list (option 119)
+func DecodeDomainSearch(data []byte) ([]string, error) {
+	var domains []string
+	for offset := 0; offset < len(data); {
+		name, next, err := decodeDNSName(data, offset)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, name)
+		offset = next
+	}
+	return domains, nil
+}
+
+// EncodeDomainSearch encodes a domain search list using DNS name
+// compression, pointing repeated suffixes at their first occurrence
+func EncodeDomainSearch(domains []string) ([]byte, error) {
+	var data []byte
+	suffixes := make(map[string]int)
+
+	for _, domain := range domains {
+		labels, err := splitDNSName(domain)
+		if err != nil {
+			return nil, err
+		}
+
+		compressed := false
+		for i := range labels {
+			suffix := strings.ToLower(strings.Join(labels[i:], "."))
+			if offset, exists := suffixes[suffix]; exists {
+				data = append(data, byte(dnsPointerMask|offset>>8), byte(offset))
+				compressed = true
+				break
+			}
+			if len(data) <= maxDNSPointer {
+				suffixes[suffix] = len(data)
+			}
+			data = append(data, byte(len(labels[i])))
+			data = append(data, labels[i]...)
+		}
+
+		if !compressed {
+			data = append(data, 0)
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDomainSearchRFC3397Example(t *testing.T) {
+	// Example from RFC 3397 section 3
+	data := []byte{
+		0x03, 'e', 'n', 'g', 0x05, 'a', 'p', 'p', 'l', 'e', 0x03, 'c', 'o', 'm', 0x00,
+		0x03, 'f', 'o', 'o', 0xc0, 0x04,
+	}
+
+	domains, err := DecodeDomainSearch(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := []string{"eng.apple.com", "foo.apple.com"}
+	if !reflect.DeepEqual(domains, want) {
+		t.Fatalf("decode: got %v, want %v", domains, want)
+	}
+
+	encoded, err := EncodeDomainSearch(want)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if !bytes.Equal(encoded, data) {
+		t.Fatalf("encode: got %v, want %v", encoded, data)
+	}
+}
+
+func TestDomainSearchRejectsPointerLoops(t *testing.T) {
+	tests := map[string][]byte{
+		"self pointer":    {0xc0, 0x00},
+		"forward pointer": {0xc0, 0x02, 0x00},
+		"label loop":      {0x01, 'a', 0xc0, 0x00},
+		"truncated label": {0x05, 'a', 'b'},
+	}
+
+	for name, data := range tests {
+		if _, err := DecodeDomainSearch(data); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLongDomainSearchSplitsAcrossOptions(t *testing.T) {
+	var domains []string
+	for _, host := range []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel"} {
+		domains = append(domains, host+".corp.example.net", host+".lab.example.org")
+	}
+	for i := 0; i < 12; i++ {
+		domains = append(domains, string(rune('a'+i))+"-site.very-long-distinct-label-number.example")
+	}
+
+	value, err := EncodeDomainSearch(domains)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if len(value) <= 255 {
+		t.Fatalf("test needs a value longer than 255 bytes, got %d", len(value))
+	}
+
+	msg := &DHCPMessage{
+		ClientHardwareAddress: make([]byte, SizeClientHardwareAddress),
+		ServerHostName:        make([]byte, SizeServerHostName),
+		BootFileName:          make([]byte, SizeBootFileName),
+		MagicCookie:           0x63825363,
+		Options:               map[byte][]byte{OptionDomainSearch: value},
+	}
+	data, err := msg.Serialize()
+	if err != nil {
+		t.Fatalf("serialize: %v", err)
+	}
+
+	parsed, err := Deserialize(data)
+	if err != nil {
+		t.Fatalf("deserialize: %v", err)
+	}
+	lease, err := NewLease(parsed)
+	if err != nil {
+		t.Fatalf("NewLease: %v", err)
+	}
+	if !reflect.DeepEqual(lease.SearchDomains, domains) {
+		t.Fatalf("got %v, want %v", lease.SearchDomains, domains)
+	}
+}
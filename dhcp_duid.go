@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DUID types (RFC 8415 section 11, RFC 6355)
+const (
+	DUIDTypeLLT  = 1 // Link-layer address plus time
+	DUIDTypeEN   = 2 // Vendor-assigned, based on enterprise number
+	DUIDTypeLL   = 3 // Link-layer address
+	DUIDTypeUUID = 4 // UUID
+)
+
+// Client identifier type used for DUID-based identifiers (RFC 4361)
+const ClientIDTypeDUID = 255
+
+// maxDUIDLength is the maximum DUID length, excluding the type code
+const maxDUIDLength = 128
+
+// duidEpoch is the base for DUID-LLT timestamps: midnight UTC, January 1, 2000
+var duidEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// DUID is a DHCP Unique Identifier in wire format
+type DUID []byte
+
+// NewDUIDLLT creates a DUID based on a link-layer address and the time
+func NewDUIDLLT(hwType uint16, t time.Time, lladdr []byte) DUID {
+	duid := binary.BigEndian.AppendUint16(nil, DUIDTypeLLT)
+	duid = binary.BigEndian.AppendUint16(duid, hwType)
+	duid = binary.BigEndian.AppendUint32(duid, uint32(t.Sub(duidEpoch)/time.Second))
+	return append(duid, lladdr...)
+}
+
+// NewDUIDEN creates a vendor-assigned DUID from an enterprise number and
+// an identifier unique to the device within that enterprise
+func NewDUIDEN(enterpriseNumber uint32, identifier []byte) DUID {
+	duid := binary.BigEndian.AppendUint16(nil, DUIDTypeEN)
+	duid = binary.BigEndian.AppendUint32(duid, enterpriseNumber)
+	return append(duid, identifier...)
+}
+
+// NewDUIDLL creates a DUID based on a link-layer address
+func NewDUIDLL(hwType uint16, lladdr []byte) DUID {
+	duid := binary.BigEndian.AppendUint16(nil, DUIDTypeLL)
+	duid = binary.BigEndian.AppendUint16(duid, hwType)
+	return append(duid, lladdr...)
+}
+
+// NewDUIDUUID creates a DUID from a UUID (RFC 6355)
+func NewDUIDUUID(uuid [16]byte) DUID {
+	duid := binary.BigEndian.AppendUint16(nil, DUIDTypeUUID)
+	return append(duid, uuid[:]...)
+}
+
+// Type returns the DUID type code
+func (d DUID) Type() uint16 {
+	if len(d) < 2 {
+		return 0
+	}
+	return binary.BigEndian.Uint16(d)
+}
+
+// Validate checks the DUID length against the rules for its type
+func (d DUID) Validate() error {
+	if len(d) < 3 {
+		return fmt.Errorf("DUID too short: %d bytes", len(d))
+	}
+	if len(d)-2 > maxDUIDLength {
+		return fmt.Errorf("DUID too long: %d bytes", len(d))
+	}
+
+	switch d.Type() {
+	case DUIDTypeLLT:
+		if len(d) < 9 {
+			return fmt.Errorf("DUID-LLT too short: %d bytes", len(d))
+		}
+	case DUIDTypeEN:
+		if len(d) < 7 {
+			return fmt.Errorf("DUID-EN too short: %d bytes", len(d))
+		}
+	case DUIDTypeLL:
+		if len(d) < 5 {
+			return fmt.Errorf("DUID-LL too short: %d bytes", len(d))
+		}
+	case DUIDTypeUUID:
+		if len(d) != 18 {
+			return fmt.Errorf("DUID-UUID must be 18 bytes, got %d", len(d))
+		}
+	}
+	return nil
+}
+
+// String returns a human-readable representation of the DUID
+func (d DUID) String() string {
+	if err := d.Validate(); err != nil {
+		return fmt.Sprintf("invalid DUID %s", hex.EncodeToString(d))
+	}
+
+	switch d.Type() {
+	case DUIDTypeLLT:
+		t := duidEpoch.Add(time.Duration(binary.BigEndian.Uint32(d[4:8])) * time.Second)
+		return fmt.Sprintf("DUID-LLT hw %d time %s addr %s", binary.BigEndian.Uint16(d[2:4]), t.Format(time.RFC3339), hardwareAddrString(d[8:]))
+	case DUIDTypeEN:
+		return fmt.Sprintf("DUID-EN enterprise %d id %s", binary.BigEndian.Uint32(d[2:6]), hex.EncodeToString(d[6:]))
+	case DUIDTypeLL:
+		return fmt.Sprintf("DUID-LL hw %d addr %s", binary.BigEndian.Uint16(d[2:4]), hardwareAddrString(d[4:]))
+	case DUIDTypeUUID:
+		u := []byte(d[2:])
+		return fmt.Sprintf("DUID-UUID %x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+	default:
+		return fmt.Sprintf("DUID type %d %s", d.Type(), hex.EncodeToString(d[2:]))
+	}
+}
+
+// EncodeDUIDClientID builds an RFC 4361 client identifier (option 61)
+// from an IAID and a DUID
+func EncodeDUIDClientID(iaid uint32, duid DUID) ([]byte, error) {
+	if err := duid.Validate(); err != nil {
+		return nil, err
+	}
+
+	value := []byte{ClientIDTypeDUID}
+	value = binary.BigEndian.AppendUint32(value, iaid)
+	return append(value, duid...), nil
+}
+
+// LoadOrCreateDUID returns the DUID stored at path, creating and storing
+// one with create if the file does not exist. Persisting the DUID keeps the
+// client identity stable across reboots and NIC changes.
+func LoadOrCreateDUID(path string, create func() (DUID, error)) (DUID, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		duid, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DUID in %s: %w", path, err)
+		}
+		if err := DUID(duid).Validate(); err != nil {
+			return nil, fmt.Errorf("invalid DUID in %s: %w", path, err)
+		}
+		return duid, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read DUID: %w", err)
+	}
+
+	duid, err := create()
+	if err != nil {
+		return nil, err
+	}
+	if err := duid.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create DUID directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(duid)+"\n"), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to store DUID: %w", err)
+	}
+
+	return duid, nil
+}
+
+// LoadOrCreateIAID returns the IAID stored at path, storing iaid there if
+// the file does not exist. The IAID is part of the RFC 4361 client
+// identifier, so it must survive MAC address changes as the DUID does.
+func LoadOrCreateIAID(path string, iaid uint32) (uint32, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse IAID in %s: %w", path, err)
+		}
+		return uint32(value), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("failed to read IAID: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create IAID directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(fmt.Sprintf("%08x\n", iaid)), 0o644); err != nil {
+		return 0, fmt.Errorf("failed to store IAID: %w", err)
+	}
+
+	return iaid, nil
+}
+
+// clientIDString returns a human-readable representation of option 61
+func clientIDString(value []byte) string {
+	if len(value) < 2 {
+		return hex.EncodeToString(value)
+	}
+
+	switch value[0] {
+	case ClientIDTypeDUID:
+		if len(value) < 5 {
+			return fmt.Sprintf("invalid DUID client identifier %s", hex.EncodeToString(value))
+		}
+		return fmt.Sprintf("IAID 0x%08x %s", binary.BigEndian.Uint32(value[1:5]), DUID(value[5:]))
+	case 0:
+		return fmt.Sprintf("Opaque: %s", opaqueString(value[1:]))
+	default:
+		return fmt.Sprintf("Type %d: %s", value[0], hardwareAddrString(value[1:]))
+	}
+}
+
+// hardwareAddrString formats a link-layer address of any length
+func hardwareAddrString(addr []byte) string {
+	parts := make([]string, len(addr))
+	for i, b := range addr {
+		parts[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(parts, ":")
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDUIDEncodings(t *testing.T) {
+	mac := []byte{0x62, 0xf9, 0xb8, 0xfc, 0x9d, 0xff}
+
+	llt := NewDUIDLLT(1, duidEpoch.Add(time.Hour), mac)
+	if !bytes.Equal(llt, []byte{0, 1, 0, 1, 0, 0, 0x0e, 0x10, 0x62, 0xf9, 0xb8, 0xfc, 0x9d, 0xff}) {
+		t.Errorf("DUID-LLT: got %x", []byte(llt))
+	}
+
+	en := NewDUIDEN(9, []byte{0xca, 0xfe})
+	if !bytes.Equal(en, []byte{0, 2, 0, 0, 0, 9, 0xca, 0xfe}) {
+		t.Errorf("DUID-EN: got %x", []byte(en))
+	}
+
+	ll := NewDUIDLL(1, mac)
+	if got := ll.String(); got != "DUID-LL hw 1 addr 62:f9:b8:fc:9d:ff" {
+		t.Errorf("DUID-LL string: got %q", got)
+	}
+
+	uuid := NewDUIDUUID([16]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 1, 2, 3, 4, 5, 6, 7, 8})
+	if got := uuid.String(); got != "DUID-UUID 12345678-9abc-def0-0102-030405060708" {
+		t.Errorf("DUID-UUID string: got %q", got)
+	}
+}
+
+func TestDUIDClientIDString(t *testing.T) {
+	value, err := EncodeDUIDClientID(0x00000002, NewDUIDLL(1, []byte{0x02, 0, 0, 0, 0, 1}))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	m := &DHCPMessage{}
+	got := m.optionValueString(OptionClientIdentifier, value)
+	if got != "IAID 0x00000002 DUID-LL hw 1 addr 02:00:00:00:00:01" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDUIDPersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "duid")
+	cfg := ClientConfig{ClientIdentifier: ClientIdentifierConfig{Mode: ClientIDDUID, DUIDPath: path}}
+
+	first, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, cfg)
+	if err != nil {
+		t.Fatalf("first client: %v", err)
+	}
+
+	// A replaced NIC has a different MAC but keeps the stored DUID
+	second, err := NewDHCPClientWithConfig([]byte{0x02, 0xaa, 0xbb, 0xcc, 0xdd, 0xee}, cfg)
+	if err != nil {
+		t.Fatalf("second client: %v", err)
+	}
+
+	firstID := first.createDHCPDiscover().Options[OptionClientIdentifier]
+	secondID := second.createDHCPDiscover().Options[OptionClientIdentifier]
+	if firstID[0] != ClientIDTypeDUID {
+		t.Fatalf("expected DUID client identifier, got type %d", firstID[0])
+	}
+	if !bytes.Equal(firstID, secondID) {
+		t.Fatalf("client identifier changed across restarts: %x vs %x", firstID, secondID)
+	}
+	if !strings.Contains(clientIDString(firstID), "DUID-LLT hw 1") {
+		t.Fatalf("unexpected client identifier %q", clientIDString(firstID))
+	}
+}
+
+func TestDUIDClientIDStableWithRandomMAC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "duid")
+	cfg := ClientConfig{
+		ClientIdentifier: ClientIdentifierConfig{Mode: ClientIDDUID, DUIDPath: path},
+		MAC:              MACConfig{Mode: MACModeRandom},
+	}
+
+	var ids [][]byte
+	for i := 0; i < 2; i++ {
+		client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, cfg)
+		if err != nil {
+			t.Fatalf("client %d: %v", i, err)
+		}
+		ids = append(ids, client.createDHCPDiscover().Options[OptionClientIdentifier])
+	}
+
+	if !bytes.Equal(ids[0], ids[1]) {
+		t.Fatalf("client identifier changed with the MAC: %x vs %x", ids[0], ids[1])
+	}
+	if _, err := os.Stat(path + ".iaid"); err != nil {
+		t.Fatalf("IAID not stored: %v", err)
+	}
+}
+
+func TestOpaqueClientIdentifier(t *testing.T) {
+	cfg := ClientConfig{ClientIdentifier: ClientIdentifierConfig{Mode: ClientIDOpaque, Opaque: []byte("\x00kiosk-7")}}
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0, 0, 0, 0, 7}, cfg)
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	msg := client.createDHCPDiscover()
+	if got := msg.optionValueString(OptionClientIdentifier, msg.Options[OptionClientIdentifier]); got != "Opaque: 'kiosk-7'" {
+		t.Fatalf("got %q", got)
+	}
+}
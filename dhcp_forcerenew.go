@@ -0,0 +1,311 @@
+package main
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// Reconfigure key authentication information types (RFC 6704 section 3)
+const (
+	ReconfigureKeyNonce = 1 // Forcerenew nonce value, sent in DHCPACK
+	ReconfigureKeyHMAC  = 2 // HMAC-MD5 digest, sent in DHCPFORCERENEW
+)
+
+// serverPort is the UDP port DHCP servers listen on
+const serverPort = 67
+
+// ForceRenewStats counts the DHCPFORCERENEW messages a bound client
+// received
+type ForceRenewStats struct {
+	Accepted uint64
+	// Dropped counts messages that failed nonce authentication
+	Dropped uint64
+}
+
+// forceRenewState holds the nonce and replay state for the current lease
+type forceRenewState struct {
+	nonce      []byte
+	lastReplay uint64
+	haveReplay bool
+	stats      ForceRenewStats
+}
+
+// ForceRenewStats returns the DHCPFORCERENEW counters
+func (c *DHCPClient) ForceRenewStats() ForceRenewStats {
+	return c.forceRenew.stats
+}
+
+// learnForceRenewNonce stores the forcerenew nonce a DHCPACK carries
+// (RFC 6704 section 3.3). A new nonce restarts replay detection.
+func (c *DHCPClient) learnForceRenewNonce(ack *DHCPMessage) {
+	auth, err := DecodeAuthentication(ack.Options[OptionAuthentication])
+	if err != nil || auth.Protocol != AuthProtocolReconfigureKey || auth.Algorithm != AuthAlgorithmHMACMD5 {
+		return
+	}
+	if len(auth.Info) != 1+sizeAuthHMAC || auth.Info[0] != ReconfigureKeyNonce {
+		return
+	}
+
+	c.forceRenew.nonce = auth.Info[1:]
+	c.forceRenew.lastReplay = auth.ReplayDetection
+	c.forceRenew.haveReplay = true
+}
+
+// verifyForceRenew checks the HMAC-MD5 digest of a DHCPFORCERENEW against
+// the nonce from the DHCPACK
+func (c *DHCPClient) verifyForceRenew(msg *DHCPMessage) error {
+	if c.forceRenew.nonce == nil {
+		return fmt.Errorf("no forcerenew nonce was received with the lease")
+	}
+
+	value, exists := msg.Options[OptionAuthentication]
+	if !exists {
+		return fmt.Errorf("message is not authenticated")
+	}
+	auth, err := DecodeAuthentication(value)
+	if err != nil {
+		return err
+	}
+	if auth.Protocol != AuthProtocolReconfigureKey || auth.Algorithm != AuthAlgorithmHMACMD5 || auth.RDM != AuthRDMCounter {
+		return fmt.Errorf("unsupported authentication: %s", auth)
+	}
+	if len(auth.Info) != 1+sizeAuthHMAC || auth.Info[0] != ReconfigureKeyHMAC {
+		return fmt.Errorf("authentication does not carry an HMAC-MD5 digest")
+	}
+	if c.forceRenew.haveReplay && auth.ReplayDetection <= c.forceRenew.lastReplay {
+		return fmt.Errorf("replayed message: replay detection 0x%016x not above 0x%016x", auth.ReplayDetection, c.forceRenew.lastReplay)
+	}
+
+	offset, length, found := findOption(msg.raw, OptionAuthentication)
+	if !found || length != len(value) {
+		return fmt.Errorf("authentication option not found in packet")
+	}
+	macOffset := offset + length - sizeAuthHMAC
+	if !hmac.Equal(msg.raw[macOffset:offset+length], authenticationHMAC(msg.raw, macOffset, c.forceRenew.nonce)) {
+		return fmt.Errorf("HMAC mismatch")
+	}
+
+	c.forceRenew.lastReplay = auth.ReplayDetection
+	c.forceRenew.haveReplay = true
+	return nil
+}
+
+// waitForForceRenew waits for an authenticated DHCPFORCERENEW. Messages
+// failing authentication are dropped and counted.
+func (c *DHCPClient) waitForForceRenew(timeout time.Duration) (*DHCPMessage, error) {
+	return c.waitForReply(timeout, func(msg *DHCPMessage) bool {
+		msgType := msg.Options[OptionDHCPMessageType]
+		if len(msgType) == 0 || msgType[0] != DHCPForceRenew {
+			return false
+		}
+		if err := c.verifyForceRenew(msg); err != nil {
+			c.forceRenew.stats.Dropped++
+			fmt.Printf("Dropping DHCPFORCERENEW: %v\n", err)
+			return false
+		}
+		c.forceRenew.stats.Accepted++
+		return true
+	})
+}
+
+// minRenewalWait keeps a lease without usable timers from renewing in a
+// tight loop
+const minRenewalWait = DefaultMinLeaseTime / 2
+
+// renewalWait returns how long to wait before renewing the lease at T1.
+// An infinite lease never needs renewing: renew is false and only a
+// DHCPFORCERENEW triggers one.
+func renewalWait(lease *Lease) (wait time.Duration, renew bool) {
+	if lease.LeaseTime == InfiniteLeaseTime {
+		return 0, false
+	}
+	wait = lease.RenewalTime
+	if wait == 0 {
+		wait = lease.LeaseTime / 2
+	}
+	return max(wait, minRenewalWait), true
+}
+
+// errLeaseLost ends a binding whose lease was refused with a DHCPNAK or
+// expired, sending the client back to INIT (RFC 2131 section 4.4.5)
+var errLeaseLost = errors.New("lease lost")
+
+// minRetransmitWait is the shortest wait between DHCPREQUEST
+// retransmissions in RENEWING and REBINDING (RFC 2131 section 4.4.5)
+const minRetransmitWait = time.Minute
+
+// rebindingWait returns how long after binding the client broadcasts to
+// any server at T2, defaulting to 7/8 of the lease time
+func rebindingWait(lease *Lease) time.Duration {
+	wait := lease.RebindingTime
+	if wait == 0 {
+		wait = lease.LeaseTime / 8 * 7
+	}
+	renewal, _ := renewalWait(lease)
+	return max(wait, renewal)
+}
+
+// retransmitWait returns how long to wait before retransmitting a
+// DHCPREQUEST: half the time left until deadline, but at least
+// minRetransmitWait and never past the deadline
+func retransmitWait(deadline time.Time) time.Duration {
+	left := time.Until(deadline)
+	return max(min(max(left/2, minRetransmitWait), left), 0)
+}
+
+// runBound keeps the lease, renewing it at T1 or as soon as an
+// authenticated DHCPFORCERENEW arrives (RFC 3203). It returns errLeaseLost
+// when the lease cannot be extended.
+func (c *DHCPClient) runBound() error {
+	for {
+		bound := time.Now()
+		wait, renew := renewalWait(c.lease)
+		if renew {
+			fmt.Printf("Bound, renewing in %s or on DHCPFORCERENEW\n", wait)
+		} else {
+			fmt.Println("Bound with an infinite lease, renewing only on DHCPFORCERENEW")
+			wait = 24 * time.Hour
+		}
+		_, err := c.waitForForceRenew(wait)
+		if err != nil {
+			if !errors.Is(err, os.ErrDeadlineExceeded) {
+				return err
+			}
+			if !renew {
+				continue
+			}
+		}
+
+		if err := c.extendLease(bound); err != nil {
+			return err
+		}
+		if err := c.configure(); err != nil {
+			return err
+		}
+	}
+}
+
+// extendLease renews the lease with its server until T2, then rebinds
+// with any server until the lease expires. An infinite lease stays bound
+// when its server does not answer.
+func (c *DHCPClient) extendLease(bound time.Time) error {
+	server := &net.UDPAddr{IP: c.lease.ServerID.AsSlice(), Port: serverPort}
+	if c.lease.LeaseTime == InfiniteLeaseTime {
+		err := c.renew(server)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			c.setState(StateBound)
+			return nil
+		}
+		return err
+	}
+
+	rebindAt := bound.Add(rebindingWait(c.lease))
+	expiresAt := bound.Add(max(c.lease.LeaseTime, rebindingWait(c.lease)))
+	for time.Now().Before(rebindAt) {
+		err := c.renew(server)
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			return err
+		}
+		time.Sleep(retransmitWait(rebindAt))
+	}
+	for time.Now().Before(expiresAt) {
+		err := c.rebind()
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			return err
+		}
+		time.Sleep(retransmitWait(expiresAt))
+	}
+
+	fmt.Println("Lease expired")
+	return errLeaseLost
+}
+
+// renew enters RENEWING and unicasts a DHCPREQUEST to the server that
+// granted the lease
+func (c *DHCPClient) renew(server *net.UDPAddr) error {
+	c.setState(StateRenewing)
+	c.transactionID = randomTransactionID()
+	fmt.Printf("Renewing lease with %s...\n", server)
+
+	data, err := c.createDHCPRenew().Serialize()
+	if err != nil {
+		return fmt.Errorf("failed to serialize message: %w", err)
+	}
+	if c.auth != nil {
+		if err := c.auth.sign(data); err != nil {
+			return fmt.Errorf("failed to authenticate message: %w", err)
+		}
+	}
+	if _, err := c.receiveSocket.WriteToUDP(data, server); err != nil {
+		return fmt.Errorf("failed to send DHCPREQUEST: %w", err)
+	}
+
+	return c.waitForRenewal()
+}
+
+// rebind enters REBINDING and broadcasts a DHCPREQUEST that any server
+// may answer
+func (c *DHCPClient) rebind() error {
+	c.setState(StateRebinding)
+	c.transactionID = randomTransactionID()
+	fmt.Println("Rebinding lease...")
+
+	if err := c.sendMessage(c.createDHCPRenew()); err != nil {
+		return fmt.Errorf("failed to send DHCPREQUEST: %w", err)
+	}
+
+	return c.waitForRenewal()
+}
+
+// waitForRenewal waits for the DHCPACK or DHCPNAK answering a renewal. A
+// DHCPNAK returns errLeaseLost.
+func (c *DHCPClient) waitForRenewal() error {
+	responseMsg, err := c.waitForReply(10*time.Second, func(msg *DHCPMessage) bool {
+		msgType := msg.Options[OptionDHCPMessageType]
+		return len(msgType) > 0 && (msgType[0] == DHCPAck || msgType[0] == DHCPNak)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to receive DHCPACK/DHCPNAK: %w", err)
+	}
+
+	if responseMsg.Options[OptionDHCPMessageType][0] == DHCPNak {
+		fmt.Printf("Received response:\n%s", responseMsg.String())
+		fmt.Println("DHCPNAK received! Lease is no longer valid.")
+		return errLeaseLost
+	}
+	return c.handleResponse(responseMsg)
+}
+
+// createDHCPRenew creates the DHCPREQUEST sent in RENEWING. The leased
+// address goes in ciaddr and options 50 and 54 are omitted (RFC 2131
+// section 4.3.2).
+func (c *DHCPClient) createDHCPRenew() *DHCPMessage {
+	msg := &DHCPMessage{
+		OpCode:         BootRequest,
+		HardwareType:   c.config.hardwareType(),
+		TransactionID:  c.transactionID,
+		ServerHostName: make([]byte, SizeServerHostName),
+		BootFileName:   make([]byte, SizeBootFileName),
+		MagicCookie:    DHCPMagicCookie,
+		Options:        make(map[byte][]byte),
+	}
+	msg.HardwareAddressLength, msg.ClientHardwareAddress = chaddrFields(msg.HardwareType, c.macAddr)
+	if LookupHardwareType(msg.HardwareType).RequiresBroadcast {
+		msg.Flags = 0x8000 // Broadcast flag
+	}
+	if c.lease != nil && c.lease.IP.Is4() {
+		ip := c.lease.IP.As4()
+		msg.ClientIP = binary.BigEndian.Uint32(ip[:])
+	}
+
+	msg.Options[OptionDHCPMessageType] = []byte{DHCPRequest}
+	msg.Options[OptionClientIdentifier] = append([]byte{msg.HardwareType}, c.macAddr...) // Hardware type + address
+	c.addClientOptions(msg)
+
+	return msg
+}
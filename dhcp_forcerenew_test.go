@@ -0,0 +1,366 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+)
+
+var testNonce = []byte("0123456789abcdef")
+
+// serverMessage builds a serialized server message. With a non-nil nonce
+// the message carries RFC 6704 authentication: the nonce itself for a
+// DHCPACK, or an HMAC keyed with it for a DHCPFORCERENEW.
+func serverMessage(t *testing.T, msgType byte, replay uint64, nonce []byte) []byte {
+	t.Helper()
+
+	msg := &DHCPMessage{
+		OpCode:                BootReply,
+		HardwareType:          HardwareTypeEthernet,
+		HardwareAddressLength: 6,
+		TransactionID:         0x12345678,
+		YourIP:                0x0a000005,
+		ClientHardwareAddress: make([]byte, SizeClientHardwareAddress),
+		ServerHostName:        make([]byte, SizeServerHostName),
+		BootFileName:          make([]byte, SizeBootFileName),
+		MagicCookie:           DHCPMagicCookie,
+		Options: map[byte][]byte{
+			OptionDHCPMessageType:    {msgType},
+			OptionServerIdentifier:   {127, 0, 0, 1},
+			OptionIPAddressLeaseTime: {0, 0, 0x0e, 0x10},
+		},
+	}
+
+	if nonce != nil {
+		auth := &Authentication{
+			Protocol:        AuthProtocolReconfigureKey,
+			Algorithm:       AuthAlgorithmHMACMD5,
+			ReplayDetection: replay,
+		}
+		if msgType == DHCPForceRenew {
+			auth.Info = append([]byte{ReconfigureKeyHMAC}, make([]byte, sizeAuthHMAC)...)
+		} else {
+			auth.Info = append([]byte{ReconfigureKeyNonce}, nonce...)
+		}
+		msg.Options[OptionAuthentication] = auth.Encode()
+	}
+
+	data, err := msg.Serialize()
+	if err != nil {
+		t.Fatalf("serialize: %v", err)
+	}
+	if nonce != nil && msgType == DHCPForceRenew {
+		offset, length, _ := findOption(data, OptionAuthentication)
+		macOffset := offset + length - sizeAuthHMAC
+		copy(data[macOffset:], authenticationHMAC(data, macOffset, nonce))
+	}
+	return data
+}
+
+func TestForceRenewNonceAuthentication(t *testing.T) {
+	client := &DHCPClient{macAddr: []byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}}
+
+	forceRenew, _ := Deserialize(serverMessage(t, DHCPForceRenew, 2, testNonce))
+	if err := client.verifyForceRenew(forceRenew); err == nil {
+		t.Fatal("expected FORCERENEW to be rejected before a nonce is known")
+	}
+
+	ack, _ := Deserialize(serverMessage(t, DHCPAck, 1, testNonce))
+	client.learnForceRenewNonce(ack)
+
+	if err := client.verifyForceRenew(forceRenew); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if err := client.verifyForceRenew(forceRenew); err == nil {
+		t.Fatal("expected replayed FORCERENEW to be rejected")
+	}
+
+	forged, _ := Deserialize(serverMessage(t, DHCPForceRenew, 3, []byte("fedcba9876543210")))
+	if err := client.verifyForceRenew(forged); err == nil {
+		t.Fatal("expected FORCERENEW signed with another nonce to be rejected")
+	}
+
+	unauthenticated, _ := Deserialize(serverMessage(t, DHCPForceRenew, 0, nil))
+	if err := client.verifyForceRenew(unauthenticated); err == nil {
+		t.Fatal("expected unauthenticated FORCERENEW to be rejected")
+	}
+}
+
+func TestForceRenewWithRequiredAuthentication(t *testing.T) {
+	auth := AuthConfig{Enabled: true, Required: true, Keys: StaticKeyStore{1: []byte("0123456789abcdef")}}
+	mac := []byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}
+	if _, err := NewDHCPClientWithConfig(mac, ClientConfig{ForceRenew: true, Authentication: auth}); err == nil {
+		t.Fatal("expected ForceRenew with required authentication to be rejected")
+	}
+
+	client, err := NewDHCPClientWithConfig(mac, ClientConfig{Authentication: auth})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	// A nonce is not an RFC 3118 HMAC, so a DHCPACK carrying only a nonce
+	// must not pass required authentication
+	ack := mustDeserialize(t, serverMessage(t, DHCPAck, 1, testNonce))
+	if err := client.auth.verify(ack); err == nil {
+		t.Fatal("expected the nonce-bearing DHCPACK to be dropped")
+	}
+}
+
+func TestForceRenewEntersRenewing(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen client: %v", err)
+	}
+	defer conn.Close()
+
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen server: %v", err)
+	}
+	defer server.Close()
+
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{ForceRenew: true})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+	client.sendSocket = conn
+	client.receiveSocket = conn
+
+	if err := client.handleResponse(mustDeserialize(t, serverMessage(t, DHCPAck, 1, testNonce))); err != nil {
+		t.Fatalf("handleResponse: %v", err)
+	}
+	if client.State() != StateBound {
+		t.Fatalf("state = %s, want BOUND", client.State())
+	}
+
+	clientAddr := conn.LocalAddr().(*net.UDPAddr)
+	unauthenticated := serverMessage(t, DHCPForceRenew, 2, nil)
+	forceRenew := serverMessage(t, DHCPForceRenew, 3, testNonce)
+	ack := serverMessage(t, DHCPAck, 4, testNonce)
+	go func() {
+		_, _ = server.WriteToUDP(unauthenticated, clientAddr)
+		_, _ = server.WriteToUDP(forceRenew, clientAddr)
+
+		buf := make([]byte, 1500)
+		_ = server.SetReadDeadline(time.Now().Add(3 * time.Second))
+		n, addr, err := server.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		request, err := Deserialize(buf[:n])
+		if err != nil {
+			t.Errorf("deserialize request: %v", err)
+			return
+		}
+		if request.ClientIP != 0x0a000005 {
+			t.Errorf("ciaddr = 0x%08x, want the leased address", request.ClientIP)
+		}
+		if _, exists := request.Options[OptionRequestedIPAddress]; exists {
+			t.Errorf("RENEWING DHCPREQUEST must not carry option 50")
+		}
+		if capable := request.Options[OptionForceRenewNonceCapable]; len(capable) != 1 || capable[0] != AuthAlgorithmHMACMD5 {
+			t.Errorf("option 145 = %v", capable)
+		}
+		_, _ = server.WriteToUDP(ack, addr)
+	}()
+
+	if _, err := client.waitForForceRenew(3 * time.Second); err != nil {
+		t.Fatalf("waitForForceRenew: %v", err)
+	}
+	if stats := client.ForceRenewStats(); stats.Accepted != 1 || stats.Dropped != 1 {
+		t.Fatalf("stats = %+v, want 1 accepted and 1 dropped", stats)
+	}
+
+	if err := client.renew(server.LocalAddr().(*net.UDPAddr)); err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if client.State() != StateBound || client.Lease().IP != netip.MustParseAddr("10.0.0.5") {
+		t.Fatalf("state %s, lease %v", client.State(), client.Lease())
+	}
+	if client.forceRenew.lastReplay != 4 {
+		t.Fatalf("replay detection not reset from the new DHCPACK: %d", client.forceRenew.lastReplay)
+	}
+}
+
+func mustDeserialize(t *testing.T, data []byte) *DHCPMessage {
+	t.Helper()
+
+	msg, err := Deserialize(data)
+	if err != nil {
+		t.Fatalf("deserialize: %v", err)
+	}
+	return msg
+}
+
+func TestRenewRequestUsesLeasedAddress(t *testing.T) {
+	client := &DHCPClient{
+		macAddr: []byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55},
+		lease:   &Lease{IP: netip.MustParseAddr("192.168.1.20")},
+	}
+
+	msg := client.createDHCPRenew()
+	if msg.ClientIP != binary.BigEndian.Uint32([]byte{192, 168, 1, 20}) || msg.Flags != 0 {
+		t.Fatalf("ciaddr 0x%08x flags 0x%04x", msg.ClientIP, msg.Flags)
+	}
+}
+
+func TestRenewalWait(t *testing.T) {
+	tests := []struct {
+		name  string
+		lease Lease
+		wait  time.Duration
+		renew bool
+	}{
+		{"T1", Lease{LeaseTime: time.Hour, RenewalTime: 20 * time.Minute}, 20 * time.Minute, true},
+		{"half the lease time", Lease{LeaseTime: time.Hour}, 30 * time.Minute, true},
+		{"no lease time", Lease{}, minRenewalWait, true},
+		{"short T1", Lease{LeaseTime: 10 * time.Second, RenewalTime: time.Second}, minRenewalWait, true},
+		{"infinite", Lease{LeaseTime: InfiniteLeaseTime}, 0, false},
+	}
+
+	for _, tt := range tests {
+		wait, renew := renewalWait(&tt.lease)
+		if wait != tt.wait || renew != tt.renew {
+			t.Errorf("%s: got %s, %t; want %s, %t", tt.name, wait, renew, tt.wait, tt.renew)
+		}
+	}
+}
+
+func TestRenewalNakLosesLease(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen client: %v", err)
+	}
+	defer conn.Close()
+
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen server: %v", err)
+	}
+	defer server.Close()
+
+	client := NewDHCPClient([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55})
+	client.receiveSocket = conn
+	client.lease = &Lease{IP: netip.MustParseAddr("10.0.0.5")}
+	client.transactionID = 0x12345678
+
+	nak := serverMessage(t, DHCPNak, 0, nil)
+	xids := make(chan uint32, 1)
+	go func() {
+		buf := make([]byte, 1500)
+		_ = server.SetReadDeadline(time.Now().Add(3 * time.Second))
+		n, addr, err := server.ReadFromUDP(buf)
+		if err != nil {
+			close(xids)
+			return
+		}
+		if request, err := Deserialize(buf[:n]); err == nil {
+			xids <- request.TransactionID
+		}
+		_, _ = server.WriteToUDP(nak, addr)
+	}()
+
+	if err := client.renew(server.LocalAddr().(*net.UDPAddr)); !errors.Is(err, errLeaseLost) {
+		t.Fatalf("renew: %v, want errLeaseLost", err)
+	}
+	if xid := <-xids; xid == 0x12345678 || xid != client.transactionID {
+		t.Fatalf("renewal xid 0x%08x, want a fresh transaction ID", xid)
+	}
+}
+
+func TestRebindBroadcastsWithoutServerID(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen client: %v", err)
+	}
+	defer conn.Close()
+
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen server: %v", err)
+	}
+	defer server.Close()
+
+	send, err := net.DialUDP("udp4", nil, server.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("dial server: %v", err)
+	}
+	defer send.Close()
+
+	var states []ClientState
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{
+		OnStateChange: func(from, to ClientState) { states = append(states, to) },
+	})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+	client.sendSocket = send
+	client.receiveSocket = conn
+	client.lease = &Lease{IP: netip.MustParseAddr("10.0.0.5")}
+
+	ack := serverMessage(t, DHCPAck, 0, nil)
+	go func() {
+		buf := make([]byte, 1500)
+		_ = server.SetReadDeadline(time.Now().Add(3 * time.Second))
+		n, _, err := server.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		request, err := Deserialize(buf[:n])
+		if err != nil {
+			t.Errorf("deserialize request: %v", err)
+			return
+		}
+		if request.ClientIP != 0x0a000005 {
+			t.Errorf("ciaddr = 0x%08x, want the leased address", request.ClientIP)
+		}
+		if _, exists := request.Options[OptionServerIdentifier]; exists {
+			t.Errorf("REBINDING DHCPREQUEST must not carry option 54")
+		}
+		_, _ = server.WriteToUDP(ack, conn.LocalAddr().(*net.UDPAddr))
+	}()
+
+	if err := client.rebind(); err != nil {
+		t.Fatalf("rebind: %v", err)
+	}
+	if !slices.Equal(states, []ClientState{StateRebinding, StateBound}) {
+		t.Fatalf("states = %v, want REBINDING then BOUND", states)
+	}
+}
+
+func TestRebindingWait(t *testing.T) {
+	tests := []struct {
+		name  string
+		lease Lease
+		wait  time.Duration
+	}{
+		{"T2", Lease{LeaseTime: time.Hour, RebindingTime: 45 * time.Minute}, 45 * time.Minute},
+		{"7/8 of the lease time", Lease{LeaseTime: 8 * time.Hour}, 7 * time.Hour},
+		{"T2 before T1", Lease{LeaseTime: time.Hour, RenewalTime: 40 * time.Minute, RebindingTime: 20 * time.Minute}, 40 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if wait := rebindingWait(&tt.lease); wait != tt.wait {
+			t.Errorf("%s: got %s, want %s", tt.name, wait, tt.wait)
+		}
+	}
+}
+
+func TestRetransmitWait(t *testing.T) {
+	if wait := retransmitWait(time.Now().Add(time.Hour)); wait < 29*time.Minute || wait > 30*time.Minute {
+		t.Errorf("an hour left: got %s, want half of it", wait)
+	}
+	if wait := retransmitWait(time.Now().Add(90 * time.Second)); wait != minRetransmitWait {
+		t.Errorf("90s left: got %s, want minRetransmitWait", wait)
+	}
+	if wait := retransmitWait(time.Now().Add(10 * time.Second)); wait > 10*time.Second {
+		t.Errorf("10s left: got %s, want no more than the time left", wait)
+	}
+	if wait := retransmitWait(time.Now().Add(-time.Second)); wait != 0 {
+		t.Errorf("deadline passed: got %s, want 0", wait)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Client FQDN option flags (RFC 4702 section 2.1)
+const (
+	FQDNFlagServerUpdate   = 0x01 // S: server should perform the A RR update
+	FQDNFlagServerOverride = 0x02 // O: server overrode the client's S flag
+	FQDNFlagEncoded        = 0x04 // E: name uses canonical wire format
+	FQDNFlagNoUpdate       = 0x08 // N: server should perform no DNS updates
+)
+
+// ClientFQDN is the content of option 81 (RFC 4702)
+type ClientFQDN struct {
+	Flags  byte
+	RCode1 byte
+	RCode2 byte
+	Name   string
+	// Partial is set when Name is not fully qualified and the server is
+	// expected to complete it
+	Partial bool
+}
+
+// Encode encodes the option in canonical wire format, setting the E flag.
+// Names without a dot are sent as partial names.
+func (f *ClientFQDN) Encode() ([]byte, error) {
+	if f.Flags&FQDNFlagNoUpdate != 0 && f.Flags&FQDNFlagServerUpdate != 0 {
+		return nil, fmt.Errorf("client FQDN flags N and S are mutually exclusive")
+	}
+
+	name, err := encodeDNSName(f.Name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client FQDN: %w", err)
+	}
+	if f.Partial || !strings.Contains(strings.TrimSuffix(f.Name, "."), ".") {
+		// Partial names omit the terminating root label
+		name = name[:len(name)-1]
+	}
+
+	data := []byte{f.Flags | FQDNFlagEncoded, f.RCode1, f.RCode2}
+	return append(data, name...), nil
+}
+
+// DecodeClientFQDN decodes option 81 in either canonical wire format or
+// the deprecated ASCII encoding
+func DecodeClientFQDN(data []byte) (*ClientFQDN, error) {
+	if len(data) < 3 {
+		return nil, fmt.Errorf("client FQDN option must be at least 3 bytes, got %d", len(data))
+	}
+
+	fqdn := &ClientFQDN{Flags: data[0], RCode1: data[1], RCode2: data[2]}
+	name := data[3:]
+
+	if fqdn.Flags&FQDNFlagEncoded == 0 {
+		fqdn.Name = string(name)
+		fqdn.Partial = !strings.HasSuffix(fqdn.Name, ".")
+		fqdn.Name = strings.TrimSuffix(fqdn.Name, ".")
+		return fqdn, nil
+	}
+
+	// Compression is not permitted in option 81, so labels are read
+	// directly. A missing root label marks a partial name.
+	var labels []string
+	fqdn.Partial = true
+	for i := 0; i < len(name); {
+		length := int(name[i])
+		if length == 0 {
+			if i != len(name)-1 {
+				return nil, fmt.Errorf("client FQDN has data after the root label")
+			}
+			fqdn.Partial = false
+			break
+		}
+		if length > maxDNSLabelLength {
+			return nil, fmt.Errorf("client FQDN label at offset %d is %d bytes", i, length)
+		}
+		if i+1+length > len(name) {
+			return nil, fmt.Errorf("truncated client FQDN label at offset %d", i)
+		}
+		labels = append(labels, string(name[i+1:i+1+length]))
+		i += 1 + length
+	}
+	fqdn.Name = strings.Join(labels, ".")
+
+	return fqdn, nil
+}
+
+// String returns a human-readable representation of the option
+func (f *ClientFQDN) String() string {
+	var flags []string
+	for _, flag := range []struct {
+		bit  byte
+		name string
+	}{
+		{FQDNFlagServerUpdate, "S"},
+		{FQDNFlagServerOverride, "O"},
+		{FQDNFlagEncoded, "E"},
+		{FQDNFlagNoUpdate, "N"},
+	} {
+		if f.Flags&flag.bit != 0 {
+			flags = append(flags, flag.name)
+		}
+	}
+
+	name := f.Name
+	if f.Partial {
+		name += " (partial)"
+	}
+	return fmt.Sprintf("'%s' flags=[%s] rcode1=%d rcode2=%d", name, strings.Join(flags, " "), f.RCode1, f.RCode2)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestClientFQDNEncodeCanonicalWireFormat(t *testing.T) {
+	fqdn := &ClientFQDN{Flags: FQDNFlagServerUpdate, Name: "kiosk1.example.com"}
+	data, err := fqdn.Encode()
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	want := []byte{FQDNFlagServerUpdate | FQDNFlagEncoded, 0, 0,
+		6, 'k', 'i', 'o', 's', 'k', '1', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("got %v, want %v", data, want)
+	}
+
+	partial, err := (&ClientFQDN{Name: "kiosk1"}).Encode()
+	if err != nil {
+		t.Fatalf("encode partial: %v", err)
+	}
+	if !bytes.Equal(partial, []byte{FQDNFlagEncoded, 0, 0, 6, 'k', 'i', 'o', 's', 'k', '1'}) {
+		t.Fatalf("partial name: got %v", partial)
+	}
+
+	if _, err := (&ClientFQDN{Flags: FQDNFlagServerUpdate | FQDNFlagNoUpdate, Name: "a.b"}).Encode(); err == nil {
+		t.Fatal("expected error when both S and N are set")
+	}
+}
+
+func TestDecodeClientFQDNServerResponse(t *testing.T) {
+	// Server overrode the client and performed the update
+	data := []byte{FQDNFlagServerUpdate | FQDNFlagServerOverride | FQDNFlagEncoded, 255, 255,
+		4, 'h', 'o', 's', 't', 3, 'l', 'a', 'b', 0}
+
+	fqdn, err := DecodeClientFQDN(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if fqdn.Name != "host.lab" || fqdn.Partial {
+		t.Fatalf("got name %q partial=%t", fqdn.Name, fqdn.Partial)
+	}
+	if got := fqdn.String(); got != "'host.lab' flags=[S O E] rcode1=255 rcode2=255" {
+		t.Fatalf("unexpected string %q", got)
+	}
+
+	ascii, err := DecodeClientFQDN(append([]byte{0, 0, 0}, "host.lab."...))
+	if err != nil {
+		t.Fatalf("decode ascii: %v", err)
+	}
+	if ascii.Name != "host.lab" || ascii.Partial {
+		t.Fatalf("ascii: got name %q partial=%t", ascii.Name, ascii.Partial)
+	}
+}
+
+func TestClientConfigChoosesNameOption(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ClientConfig
+		want12 bool
+		want81 bool
+	}{
+		{"hostname only", ClientConfig{Hostname: "kiosk1"}, true, false},
+		{"fqdn preferred", ClientConfig{Hostname: "kiosk1", FQDN: "kiosk1.example.com"}, false, true},
+		{"forced hostname", ClientConfig{FQDN: "kiosk1.example.com", SendName: NameOptionHostname}, true, false},
+		{"none", ClientConfig{Hostname: "kiosk1", SendName: NameOptionNone}, false, false},
+	}
+
+	for _, tt := range tests {
+		options, err := tt.config.buildClientOptions(nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		_, has12 := options[OptionHostName]
+		_, has81 := options[OptionClientFQDN]
+		if has12 != tt.want12 || has81 != tt.want81 {
+			t.Errorf("%s: got option 12=%t 81=%t, want 12=%t 81=%t", tt.name, has12, has81, tt.want12, tt.want81)
+		}
+	}
+
+	options, _ := ClientConfig{FQDN: "kiosk1.example.com", SendName: NameOptionHostname}.buildClientOptions(nil)
+	if string(options[OptionHostName]) != "kiosk1" {
+		t.Errorf("hostname derived from FQDN: got %q", options[OptionHostName])
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import "fmt"
+
+// ARP hardware types (IANA "Hardware Types" registry)
+const (
+	HardwareTypeEthernet   = 1
+	HardwareTypeIEEE802    = 6
+	HardwareTypeFrameRelay = 15
+	HardwareTypeATM        = 16
+	HardwareTypeFireWire   = 24
+	HardwareTypeInfiniBand = 32
+)
+
+// HardwareTypeInfo describes how a hardware type is carried in DHCP
+type HardwareTypeInfo struct {
+	Name string
+	// AddressLength is the link-layer address length, or 0 when it varies
+	AddressLength int
+	// EmptyChaddr is set when hlen must be 0 and chaddr left zero because
+	// the address does not fit (RFC 4390, RFC 2855)
+	EmptyChaddr bool
+	// RequiresClientID is set when the link-layer address cannot identify
+	// the client, which must send an RFC 4361 identifier in option 61
+	RequiresClientID bool
+	// RequiresBroadcast is set when the client must set the broadcast flag
+	// even when it knows its address (RFC 4390)
+	RequiresBroadcast bool
+}
+
+// hardwareTypes holds the hardware types the client knows how to handle
+var hardwareTypes = map[uint8]HardwareTypeInfo{
+	HardwareTypeEthernet:   {Name: "Ethernet", AddressLength: 6},
+	HardwareTypeIEEE802:    {Name: "IEEE 802", AddressLength: 6},
+	HardwareTypeFrameRelay: {Name: "Frame Relay"},
+	HardwareTypeATM:        {Name: "Asynchronous Transfer Mode (ATM)"},
+	HardwareTypeFireWire: {
+		Name:             "IEEE 1394 (FireWire)",
+		AddressLength:    16,
+		EmptyChaddr:      true,
+		RequiresClientID: true,
+	},
+	HardwareTypeInfiniBand: {
+		Name:              "InfiniBand",
+		AddressLength:     20,
+		EmptyChaddr:       true,
+		RequiresClientID:  true,
+		RequiresBroadcast: true,
+	},
+}
+
+// LookupHardwareType returns the rules for a hardware type. Unknown types
+// are treated as variable-length addresses carried in chaddr.
+func LookupHardwareType(htype uint8) HardwareTypeInfo {
+	if info, exists := hardwareTypes[htype]; exists {
+		return info
+	}
+	return HardwareTypeInfo{Name: "Unknown"}
+}
+
+// ValidateHardwareAddress checks a link-layer address against the rules
+// for its hardware type
+func ValidateHardwareAddress(htype uint8, addr []byte) error {
+	info := LookupHardwareType(htype)
+	if info.AddressLength != 0 && len(addr) != info.AddressLength {
+		return fmt.Errorf("%s address must be %d bytes, got %d", info.Name, info.AddressLength, len(addr))
+	}
+	if len(addr) == 0 {
+		return fmt.Errorf("%s address is empty", info.Name)
+	}
+	if !info.EmptyChaddr && len(addr) > SizeClientHardwareAddress {
+		return fmt.Errorf("%s address of %d bytes does not fit in chaddr", info.Name, len(addr))
+	}
+	if (htype == HardwareTypeEthernet || htype == HardwareTypeIEEE802) && addr[0]&0x01 != 0 {
+		return fmt.Errorf("multicast address %s is not valid for a DHCP client", hardwareAddrString(addr))
+	}
+	return nil
+}
+
+// chaddrFields returns the hlen and chaddr values for a link-layer address
+func chaddrFields(htype uint8, addr []byte) (uint8, []byte) {
+	chaddr := make([]byte, SizeClientHardwareAddress)
+	if LookupHardwareType(htype).EmptyChaddr {
+		return 0, chaddr
+	}
+	n := copy(chaddr, addr)
+	return uint8(n), chaddr
+}
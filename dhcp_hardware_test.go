@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInfiniBandDiscoverFollowsRFC4390(t *testing.T) {
+	guid := make([]byte, 20)
+	for i := range guid {
+		guid[i] = byte(0x80 + i)
+	}
+
+	client, err := NewDHCPClientWithConfig(guid, ClientConfig{HardwareType: HardwareTypeInfiniBand})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	msg := client.createDHCPDiscover()
+	if msg.HardwareType != HardwareTypeInfiniBand || msg.HardwareAddressLength != 0 {
+		t.Fatalf("got htype %d hlen %d, want 32 and 0", msg.HardwareType, msg.HardwareAddressLength)
+	}
+	for _, b := range msg.ClientHardwareAddress {
+		if b != 0 {
+			t.Fatalf("chaddr must be zero on InfiniBand, got %x", msg.ClientHardwareAddress)
+		}
+	}
+	if msg.Flags&0x8000 == 0 {
+		t.Fatal("broadcast flag must be set on InfiniBand")
+	}
+
+	clientID := msg.Options[OptionClientIdentifier]
+	if len(clientID) == 0 || clientID[0] != ClientIDTypeDUID {
+		t.Fatalf("expected RFC 4361 client identifier, got %x", clientID)
+	}
+	if !strings.Contains(clientIDString(clientID), "DUID-LL hw 32") {
+		t.Fatalf("unexpected client identifier %q", clientIDString(clientID))
+	}
+	if _, err := msg.Serialize(); err != nil {
+		t.Fatalf("serialize: %v", err)
+	}
+	if got := msg.hardwareAddressString(); got != "(none)" {
+		t.Fatalf("hardware address string: got %q", got)
+	}
+	if renew := client.createDHCPRenew(); renew.Flags&0x8000 == 0 {
+		t.Fatal("broadcast flag must be set on InfiniBand even when renewing")
+	}
+}
+
+func TestFireWireSendsDUIDClientID(t *testing.T) {
+	client, err := NewDHCPClientWithConfig(make([]byte, 16), ClientConfig{HardwareType: HardwareTypeFireWire})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	msg := client.createDHCPRenew()
+	if clientID := msg.Options[OptionClientIdentifier]; len(clientID) == 0 || clientID[0] != ClientIDTypeDUID {
+		t.Fatalf("expected RFC 4361 client identifier, got %x", clientID)
+	}
+	if msg.Flags != 0 {
+		t.Fatalf("unexpected flags 0x%04x", msg.Flags)
+	}
+}
+
+func TestValidateHardwareAddress(t *testing.T) {
+	tests := []struct {
+		name  string
+		htype uint8
+		addr  []byte
+		ok    bool
+	}{
+		{"ethernet", HardwareTypeEthernet, []byte{0x02, 0, 0, 0, 0, 1}, true},
+		{"ethernet multicast", HardwareTypeEthernet, []byte{0x01, 0, 0x5e, 0, 0, 1}, false},
+		{"ethernet short", HardwareTypeEthernet, []byte{0x02, 0, 0}, false},
+		{"infiniband", HardwareTypeInfiniBand, make([]byte, 20), true},
+		{"infiniband short", HardwareTypeInfiniBand, make([]byte, 6), false},
+		{"unknown type", 99, []byte{1, 2, 3, 4, 5, 6, 7, 8}, true},
+		{"too long for chaddr", 99, make([]byte, 17), false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateHardwareAddress(tt.htype, tt.addr)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: got error %v, want ok=%t", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestHardwareAddressStringUsesHlen(t *testing.T) {
+	msg := &DHCPMessage{HardwareType: 99, HardwareAddressLength: 8, ClientHardwareAddress: make([]byte, 16)}
+	copy(msg.ClientHardwareAddress, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	if got := msg.hardwareAddressString(); got != "01:02:03:04:05:06:07:08" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// MinV6OnlyWait is the shortest time DHCPv4 is paused for an IPv6-only
+// preferred network (MIN_V6ONLY_WAIT, RFC 8925 section 3.4)
+const MinV6OnlyWait = 300 * time.Second
+
+// errIPv6OnlyPreferred ends an exchange in which the server asked the
+// client to stay IPv6-only
+var errIPv6OnlyPreferred = errors.New("server prefers IPv6-only operation")
+
+// v6OnlyPreferred reports whether a reply asks the client to stay
+// IPv6-only. The option only counts when the client requested it, and a
+// value that is not 4 bytes is ignored (RFC 8925 section 3.3).
+func (c *DHCPClient) v6OnlyPreferred(msg *DHCPMessage) bool {
+	return c.config.IPv6OnlyPreferred && len(msg.Options[OptionIPv6OnlyPreferred]) == 4
+}
+
+// enterV6OnlyWait stops the exchange without configuring IPv4 when a
+// DHCPOFFER (or rapid commit DHCPACK) carries option 108. The returned
+// lease records how long DHCPv4 stays paused.
+func (c *DHCPClient) enterV6OnlyWait(msg *DHCPMessage) error {
+	lease, err := c.newLease(msg)
+	if err != nil {
+		return fmt.Errorf("failed to parse lease: %w", err)
+	}
+
+	// Values below the minimum, including the 4-byte all-zero value, are
+	// raised to MIN_V6ONLY_WAIT
+	lease.V6OnlyWait = max(lease.V6OnlyWait, MinV6OnlyWait)
+	lease.IP = netip.Addr{}
+	c.lease = lease
+	c.setState(StateV6OnlyWait)
+
+	fmt.Printf("Server prefers IPv6-only, pausing DHCPv4 for %s\n", lease.V6OnlyWait)
+	fmt.Print(lease.String())
+	return errIPv6OnlyPreferred
+}
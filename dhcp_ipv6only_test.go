@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIPv6OnlyPreferredRequested(t *testing.T) {
+	client := &DHCPClient{
+		macAddr: []byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55},
+		config:  ClientConfig{IPv6OnlyPreferred: true},
+	}
+
+	prl := client.createDHCPDiscover().Options[OptionParameterRequestList]
+	if !bytes.Contains(prl, []byte{OptionIPv6OnlyPreferred}) {
+		t.Fatalf("parameter request list %v is missing option 108", prl)
+	}
+
+	client.config.IPv6OnlyPreferred = false
+	prl = client.createDHCPDiscover().Options[OptionParameterRequestList]
+	if bytes.Contains(prl, []byte{OptionIPv6OnlyPreferred}) {
+		t.Fatalf("option 108 requested without being enabled: %v", prl)
+	}
+}
+
+func TestEnterV6OnlyWait(t *testing.T) {
+	var transitions []ClientState
+	client := &DHCPClient{
+		config: ClientConfig{
+			IPv6OnlyPreferred: true,
+			OnStateChange: func(from, to ClientState) {
+				transitions = append(transitions, to)
+			},
+		},
+	}
+
+	tests := []struct {
+		value []byte
+		want  time.Duration
+	}{
+		{[]byte{0, 0, 0x07, 0x08}, 1800 * time.Second},
+		{[]byte{0, 0, 0, 0}, MinV6OnlyWait},
+		{[]byte{0, 0, 0, 60}, MinV6OnlyWait},
+	}
+	for _, tt := range tests {
+		offer := &DHCPMessage{
+			YourIP: 0x0a000005,
+			Options: map[byte][]byte{
+				OptionDHCPMessageType:   {DHCPOffer},
+				OptionServerIdentifier:  {10, 0, 0, 1},
+				OptionIPv6OnlyPreferred: tt.value,
+			},
+		}
+
+		if err := client.enterV6OnlyWait(offer); !errors.Is(err, errIPv6OnlyPreferred) {
+			t.Fatalf("enterV6OnlyWait(%v) = %v", tt.value, err)
+		}
+		lease := client.Lease()
+		if lease.V6OnlyWait != tt.want {
+			t.Errorf("V6OnlyWait for %v = %s, want %s", tt.value, lease.V6OnlyWait, tt.want)
+		}
+		if lease.IP.IsValid() {
+			t.Errorf("IPv4 address %s configured while IPv6-only", lease.IP)
+		}
+	}
+
+	if client.State() != StateV6OnlyWait || len(transitions) != 1 || transitions[0] != StateV6OnlyWait {
+		t.Fatalf("state %s, transitions %v", client.State(), transitions)
+	}
+}
+
+func TestLeaseV6OnlyWait(t *testing.T) {
+	msg := &DHCPMessage{Options: map[byte][]byte{OptionIPv6OnlyPreferred: {0, 0, 0x01, 0x2c}}}
+
+	lease, err := NewLease(msg)
+	if err != nil {
+		t.Fatalf("NewLease: %v", err)
+	}
+	if lease.V6OnlyWait != 300*time.Second {
+		t.Fatalf("V6OnlyWait = %s", lease.V6OnlyWait)
+	}
+
+	// A malformed option 108 is ignored rather than failing the lease
+	msg.Options[OptionIPv6OnlyPreferred] = []byte{1}
+	lease, err = NewLease(msg)
+	if err != nil {
+		t.Fatalf("NewLease with malformed option 108: %v", err)
+	}
+	if lease.V6OnlyWait != 0 {
+		t.Fatalf("V6OnlyWait = %s for a malformed option", lease.V6OnlyWait)
+	}
+
+	client := &DHCPClient{config: ClientConfig{IPv6OnlyPreferred: true}}
+	if client.v6OnlyPreferred(msg) {
+		t.Fatal("malformed option 108 honoured")
+	}
+
+	// Option 108 is only honoured when the client requested it
+	msg.Options[OptionIPv6OnlyPreferred] = []byte{0, 0, 0x01, 0x2c}
+	client.config.IPv6OnlyPreferred = false
+	if lease, err = client.newLease(msg); err != nil || lease.V6OnlyWait != 0 {
+		t.Fatalf("unrequested option 108 honoured: %v, %v", lease, err)
+	}
+	if client.v6OnlyPreferred(msg) {
+		t.Fatal("unrequested option 108 honoured")
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// InfiniteLeaseTime is the lease time of a permanent assignment, such as
+// a BOOTP reply or a DHCP lease time of 0xffffffff
+const InfiniteLeaseTime = time.Duration(1<<63 - 1)
+
+// Lease holds the configuration a DHCP server assigned in a DHCPACK
+type Lease struct {
+	IP            netip.Addr
+	SubnetMask    netip.Addr
+	ServerID      netip.Addr
+	Routers       []netip.Addr
+	Routes        []Route
+	DNSServers    []netip.Addr
+	Hostname      string
+	FQDN          *ClientFQDN
+	DomainName    string
+	SearchDomains []string
+	LeaseTime     time.Duration
+	RenewalTime   time.Duration
+	RebindingTime time.Duration
+	Vendor        *VendorInfo
+	VIVendorInfo  []VIVendorInfo
+	// VendorError and VIVendorError say why option 43 or option 125 could
+	// not be decoded. Vendor settings are advisory, so the lease stands.
+	VendorError   error
+	VIVendorError error
+	// V6OnlyWait is set when the server prefers IPv6-only operation
+	// (option 108, RFC 8925). IPv4 is not configured for that long.
+	V6OnlyWait time.Duration
+	// CaptivePortal is the captive portal API URI (option 114, RFC 8910)
+	CaptivePortal string
+	// ProxyAutoConfig is the WPAD proxy auto-config URL (option 252)
+	ProxyAutoConfig string
+	// MTU is the interface MTU from option 26
+	MTU int
+	// NTPServers are the NTP servers from option 42
+	NTPServers []netip.Addr
+	// POSIXTimeZone is a POSIX TZ string (option 100, RFC 4833)
+	POSIXTimeZone string
+	// TimeZone is a tz database name such as Europe/Zurich (option 101)
+	TimeZone string
+	// SZTPBootstrapServers are the SZTP bootstrap server URIs (option 143,
+	// RFC 8572)
+	SZTPBootstrapServers []string
+	// ConfigURL is the configuration file URL from option 67
+	ConfigURL string
+}
+
+// NewLease builds a Lease from a DHCPACK message. Option 43 is decoded for
+// the vendor class the server echoes in option 60.
+func NewLease(msg *DHCPMessage) (*Lease, error) {
+	return NewLeaseForVendorClass(msg, "")
+}
+
+// newLease builds a Lease from a reply to this client. Option 108 is only
+// honoured when the client requested it.
+func (c *DHCPClient) newLease(msg *DHCPMessage) (*Lease, error) {
+	lease, err := NewLeaseForVendorClass(msg, string(c.clientOptions[OptionVendorClassIdentifier]))
+	if err != nil {
+		return nil, err
+	}
+	if !c.config.IPv6OnlyPreferred {
+		lease.V6OnlyWait = 0
+	}
+	return lease, nil
+}
+
+// NewLeaseForVendorClass builds a Lease from a DHCPACK message, decoding
+// option 43 for vendorClass when the server does not echo option 60.
+// Servers choose the option 43 format from the class the client sent and
+// rarely return it.
+func NewLeaseForVendorClass(msg *DHCPMessage, vendorClass string) (*Lease, error) {
+	lease := &Lease{
+		IP: uint32ToAddr(msg.YourIP),
+	}
+
+	var err error
+	if lease.SubnetMask, err = optionAddr(msg, OptionSubnetMask); err != nil {
+		return nil, err
+	}
+	if lease.ServerID, err = optionAddr(msg, OptionServerIdentifier); err != nil {
+		return nil, err
+	}
+	if lease.DNSServers, err = optionAddrList(msg, OptionDomainNameServer); err != nil {
+		return nil, err
+	}
+	if value, exists := msg.Options[OptionHostName]; exists {
+		lease.Hostname = msg.bytesToString(value)
+	}
+	if value, exists := msg.Options[OptionClientFQDN]; exists {
+		if lease.FQDN, err = DecodeClientFQDN(value); err != nil {
+			return nil, fmt.Errorf("failed to decode option %d: %w", OptionClientFQDN, err)
+		}
+	}
+	if value, exists := msg.Options[OptionDomainName]; exists {
+		lease.DomainName = msg.bytesToString(value)
+	}
+	if value, exists := msg.Options[OptionDomainSearch]; exists {
+		if lease.SearchDomains, err = DecodeDomainSearch(value); err != nil {
+			return nil, fmt.Errorf("failed to decode option %d: %w", OptionDomainSearch, err)
+		}
+	}
+	if lease.LeaseTime, err = optionSeconds(msg, OptionIPAddressLeaseTime); err != nil {
+		return nil, err
+	}
+	if value := msg.Options[OptionIPAddressLeaseTime]; len(value) == 4 && binary.BigEndian.Uint32(value) == 0xffffffff {
+		lease.LeaseTime = InfiniteLeaseTime
+	}
+	if lease.RenewalTime, err = optionSeconds(msg, OptionRenewalTime); err != nil {
+		return nil, err
+	}
+	if lease.RebindingTime, err = optionSeconds(msg, OptionRebindingTime); err != nil {
+		return nil, err
+	}
+	// A malformed option 108 is ignored (RFC 8925 section 3.3)
+	lease.V6OnlyWait, _ = optionSeconds(msg, OptionIPv6OnlyPreferred)
+
+	if value, exists := msg.Options[OptionInterfaceMTU]; exists {
+		if len(value) != 2 {
+			return nil, fmt.Errorf("option %d must be 2 bytes, got %d", OptionInterfaceMTU, len(value))
+		}
+		if lease.MTU = int(binary.BigEndian.Uint16(value)); lease.MTU < MinMTU {
+			return nil, fmt.Errorf("invalid MTU %d in option %d", lease.MTU, OptionInterfaceMTU)
+		}
+	}
+	if lease.NTPServers, err = optionAddrList(msg, OptionNTPServers); err != nil {
+		return nil, err
+	}
+	if value, exists := msg.Options[OptionPOSIXTimeZone]; exists {
+		lease.POSIXTimeZone = msg.bytesToString(value)
+	}
+	if value, exists := msg.Options[OptionTZDatabaseName]; exists {
+		lease.TimeZone = msg.bytesToString(value)
+	}
+	if value, exists := msg.Options[OptionSZTPRedirect]; exists {
+		if lease.SZTPBootstrapServers, err = DecodeSZTPRedirect(value); err != nil {
+			return nil, fmt.Errorf("failed to decode option %d: %w", OptionSZTPRedirect, err)
+		}
+	}
+	lease.ConfigURL = configFileURL(msg)
+	if value, exists := msg.Options[OptionCaptivePortal]; exists {
+		lease.CaptivePortal = msg.bytesToString(value)
+	}
+	if value, exists := msg.Options[OptionProxyAutoConfig]; exists {
+		lease.ProxyAutoConfig = msg.bytesToString(value)
+	}
+
+	// Vendor settings are advisory, so a payload the vendor decoder does not
+	// understand leaves Vendor unset rather than failing the lease
+	if value, exists := msg.Options[OptionVendorSpecific]; exists {
+		if echoed, exists := msg.Options[OptionVendorClassIdentifier]; exists {
+			vendorClass = msg.bytesToString(echoed)
+		}
+		lease.Vendor, lease.VendorError = DecodeVendorSpecific(vendorClass, value)
+	}
+
+	if value, exists := msg.Options[OptionVIVendorSpecific]; exists {
+		lease.VIVendorInfo, lease.VIVendorError = DecodeVIVendorInfo(value)
+	}
+
+	// RFC 3442: when classless static routes are present the client must
+	// ignore the Router option. Option 249 is Microsoft's pre-standard copy
+	// of option 121 and is only used when 121 is absent.
+	routeOption := byte(OptionClasslessStaticRoute)
+	if _, exists := msg.Options[routeOption]; !exists {
+		routeOption = OptionMSClasslessStaticRoute
+	}
+	if value, exists := msg.Options[routeOption]; exists {
+		if lease.Routes, err = DecodeClasslessRoutes(value); err != nil {
+			return nil, fmt.Errorf("failed to decode option %d: %w", routeOption, err)
+		}
+	} else if lease.Routers, err = optionAddrList(msg, OptionRouter); err != nil {
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// String returns a human-readable summary of the lease
+func (l *Lease) String() string {
+	var result strings.Builder
+
+	result.WriteString("DHCP Lease:\n")
+	if l.IP.IsValid() || l.V6OnlyWait == 0 {
+		result.WriteString(fmt.Sprintf("  IP Address: %s\n", l.IP))
+	}
+	if l.V6OnlyWait > 0 {
+		result.WriteString(fmt.Sprintf("  IPv6-Only Preferred: IPv4 paused for %s\n", l.V6OnlyWait))
+	}
+	if l.SubnetMask.IsValid() {
+		result.WriteString(fmt.Sprintf("  Subnet Mask: %s\n", l.SubnetMask))
+	}
+	if l.ServerID.IsValid() {
+		result.WriteString(fmt.Sprintf("  Server: %s\n", l.ServerID))
+	}
+	if len(l.Routers) > 0 {
+		result.WriteString(fmt.Sprintf("  Routers: %s\n", joinAddrs(l.Routers)))
+	}
+	for _, route := range l.Routes {
+		result.WriteString(fmt.Sprintf("  Route: %s\n", route))
+	}
+	if len(l.DNSServers) > 0 {
+		result.WriteString(fmt.Sprintf("  DNS Servers: %s\n", joinAddrs(l.DNSServers)))
+	}
+	if l.Hostname != "" {
+		result.WriteString(fmt.Sprintf("  Hostname: %s\n", l.Hostname))
+	}
+	if l.FQDN != nil {
+		result.WriteString(fmt.Sprintf("  Client FQDN: %s\n", l.FQDN))
+	}
+	if l.DomainName != "" {
+		result.WriteString(fmt.Sprintf("  Domain Name: %s\n", l.DomainName))
+	}
+	if len(l.SearchDomains) > 0 {
+		result.WriteString(fmt.Sprintf("  Search Domains: %s\n", strings.Join(l.SearchDomains, ", ")))
+	}
+	if l.LeaseTime == InfiniteLeaseTime {
+		result.WriteString("  Lease Time: infinite\n")
+	} else if l.LeaseTime > 0 {
+		result.WriteString(fmt.Sprintf("  Lease Time: %s\n", l.LeaseTime))
+	}
+	if l.RenewalTime > 0 {
+		result.WriteString(fmt.Sprintf("  Renewal Time: %s\n", l.RenewalTime))
+	}
+	if l.RebindingTime > 0 {
+		result.WriteString(fmt.Sprintf("  Rebinding Time: %s\n", l.RebindingTime))
+	}
+	if l.MTU > 0 {
+		result.WriteString(fmt.Sprintf("  MTU: %d\n", l.MTU))
+	}
+	if len(l.NTPServers) > 0 {
+		result.WriteString(fmt.Sprintf("  NTP Servers: %s\n", joinAddrs(l.NTPServers)))
+	}
+	if l.TimeZone != "" {
+		result.WriteString(fmt.Sprintf("  Time Zone: %s\n", l.TimeZone))
+	}
+	if l.POSIXTimeZone != "" {
+		result.WriteString(fmt.Sprintf("  POSIX Time Zone: %s\n", l.POSIXTimeZone))
+	}
+	for _, uri := range l.SZTPBootstrapServers {
+		result.WriteString(fmt.Sprintf("  SZTP Bootstrap Server: %s\n", uri))
+	}
+	if l.ConfigURL != "" {
+		result.WriteString(fmt.Sprintf("  Config File: %s\n", l.ConfigURL))
+	}
+	if l.CaptivePortal != "" {
+		result.WriteString(fmt.Sprintf("  Captive Portal: %s\n", l.CaptivePortal))
+	}
+	if l.ProxyAutoConfig != "" {
+		result.WriteString(fmt.Sprintf("  Proxy Auto-Config: %s\n", l.ProxyAutoConfig))
+	}
+	if l.Vendor != nil {
+		result.WriteString(fmt.Sprintf("  Vendor Settings (%s):\n", l.Vendor.Vendor))
+		for _, setting := range l.Vendor.Settings {
+			result.WriteString(fmt.Sprintf("    %s: %s\n", setting.Name, setting.Value))
+		}
+	}
+	for _, viVendor := range l.VIVendorInfo {
+		info := viVendor.Decode()
+		result.WriteString(fmt.Sprintf("  Vendor Settings (%s):\n", info.Vendor))
+		for _, setting := range info.Settings {
+			result.WriteString(fmt.Sprintf("    %s: %s\n", setting.Name, setting.Value))
+		}
+	}
+	if l.VendorError != nil {
+		result.WriteString(fmt.Sprintf("  Undecodable Vendor Settings: %v\n", l.VendorError))
+	}
+	if l.VIVendorError != nil {
+		result.WriteString(fmt.Sprintf("  Undecodable V-I Vendor Settings: %v\n", l.VIVendorError))
+	}
+
+	return result.String()
+}
+
+// HasCaptivePortal reports whether the network announced a captive portal
+// API
+func (l *Lease) HasCaptivePortal() bool {
+	return l.CaptivePortal != "" && l.CaptivePortal != CaptivePortalUnrestricted
+}
+
+// Helper functions for decoding lease options
+func uint32ToAddr(ip uint32) netip.Addr {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], ip)
+	return netip.AddrFrom4(b)
+}
+
+func optionAddr(msg *DHCPMessage, code byte) (netip.Addr, error) {
+	value, exists := msg.Options[code]
+	if !exists {
+		return netip.Addr{}, nil
+	}
+	if len(value) != 4 {
+		return netip.Addr{}, fmt.Errorf("option %d must be 4 bytes, got %d", code, len(value))
+	}
+	return netip.AddrFrom4([4]byte(value)), nil
+}
+
+func optionAddrList(msg *DHCPMessage, code byte) ([]netip.Addr, error) {
+	value, exists := msg.Options[code]
+	if !exists {
+		return nil, nil
+	}
+	if len(value) == 0 || len(value)%4 != 0 {
+		return nil, fmt.Errorf("option %d must be a multiple of 4 bytes, got %d", code, len(value))
+	}
+
+	addrs := make([]netip.Addr, 0, len(value)/4)
+	for i := 0; i < len(value); i += 4 {
+		addrs = append(addrs, netip.AddrFrom4([4]byte(value[i:i+4])))
+	}
+	return addrs, nil
+}
+
+func optionSeconds(msg *DHCPMessage, code byte) (time.Duration, error) {
+	value, exists := msg.Options[code]
+	if !exists {
+		return 0, nil
+	}
+	if len(value) != 4 {
+		return 0, fmt.Errorf("option %d must be 4 bytes, got %d", code, len(value))
+	}
+	return time.Duration(binary.BigEndian.Uint32(value)) * time.Second, nil
+}
+
+func joinAddrs(addrs []netip.Addr) string {
+	var parts []string
+	for _, addr := range addrs {
+		parts = append(parts, addr.String())
+	}
+	return strings.Join(parts, ", ")
+}
@@ -12,12 +12,14 @@ func mockDHCPServer(t *testing.T, serverPort int, replyPort int, done <-chan str
 
 	addr, err := net.ResolveUDPAddr("udp4", net.JoinHostPort("127.0.0.1", itoa(serverPort)))
 	if err != nil {
-		t.Fatalf("resolve server addr: %v", err)
+		t.Errorf("resolve server addr: %v", err)
+		return
 	}
 
 	conn, err := net.ListenUDP("udp4", addr)
 	if err != nil {
-		t.Fatalf("listen mock server: %v", err)
+		t.Errorf("listen mock server: %v", err)
+		return
 	}
 	defer conn.Close()
 
@@ -128,8 +130,8 @@ func TestHardcodedMACIsValidFormat(t *testing.T) {
 	if len(mac) != 6 {
 		t.Fatalf("expected 6 bytes, got %d", len(mac))
 	}
-	if mac[0]&1 != 0 {
-		t.Fatalf("multicast MAC not valid for DHCP client chaddr: %x", mac[0])
+	if !IsLocalUnicast(mac) {
+		t.Fatalf("MAC is not locally administered unicast: %x", mac[0])
 	}
 }
 
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MACMode selects the hardware address the client sends in chaddr and
+// the client identifier
+type MACMode uint8
+
+// Hardware address modes
+const (
+	MACModeHardware MACMode = iota // The address the client was created with
+	MACModeRandom                  // A new random address on every start
+	MACModeStable                  // Derived from a secret, stable per network
+)
+
+// MinMACSecretLength is the shortest secret accepted for stable addresses
+const MinMACSecretLength = 16
+
+// MACConfig configures hardware address generation
+type MACConfig struct {
+	Mode MACMode
+	// Secret keys the stable address. Anyone holding it can link the
+	// addresses used on different networks, so keep it private.
+	Secret []byte
+	// NetworkID identifies the network, e.g. the Wi-Fi SSID. Together with
+	// the interface name it selects the stable address.
+	NetworkID string
+}
+
+// RandomMAC returns a random locally administered unicast address
+func RandomMAC() (net.HardwareAddr, error) {
+	mac := make(net.HardwareAddr, 6)
+	if _, err := rand.Read(mac); err != nil {
+		return nil, fmt.Errorf("failed to generate MAC address: %w", err)
+	}
+	setLocalUnicast(mac)
+	return mac, nil
+}
+
+// StableMAC derives a locally administered unicast address from an
+// HMAC-SHA256 of the interface name and network identifier. The same
+// inputs always give the same address, so leases survive restarts, while
+// addresses on different networks cannot be linked without the secret.
+func StableMAC(secret []byte, iface, networkID string) (net.HardwareAddr, error) {
+	if len(secret) < MinMACSecretLength {
+		return nil, fmt.Errorf("MAC secret must be at least %d bytes, got %d", MinMACSecretLength, len(secret))
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(iface))
+	mac.Write([]byte{0}) // Interface names cannot contain NUL
+	mac.Write([]byte(networkID))
+
+	addr := net.HardwareAddr(mac.Sum(nil)[:6])
+	setLocalUnicast(addr)
+	return addr, nil
+}
+
+// DefaultMACSecretPath is where the stable address secret is kept unless
+// configured otherwise. It does not depend on the working directory, so
+// the address stays the same however the client is started.
+const DefaultMACSecretPath = "/var/lib/dhcp-client/mac-secret"
+
+// LoadOrCreateMACSecret returns the stable address secret stored at path,
+// generating and storing a random one if the file does not exist. The file
+// is only readable by its owner.
+func LoadOrCreateMACSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		secret, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse MAC secret in %s: %w", path, err)
+		}
+		if len(secret) < MinMACSecretLength {
+			return nil, fmt.Errorf("MAC secret in %s must be at least %d bytes, got %d", path, MinMACSecretLength, len(secret))
+		}
+		return secret, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read MAC secret: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate MAC secret: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create MAC secret directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(secret)+"\n"), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to store MAC secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// IsLocalUnicast reports whether mac is a locally administered unicast
+// address: the I/G bit is clear and the U/L bit is set
+func IsLocalUnicast(mac []byte) bool {
+	return len(mac) > 0 && mac[0]&0x01 == 0 && mac[0]&0x02 != 0
+}
+
+// setLocalUnicast clears the I/G bit and sets the U/L bit of mac
+func setLocalUnicast(mac []byte) {
+	mac[0] = mac[0]&^0x01 | 0x02
+}
+
+// hardwareAddress returns the address the client uses in place of macAddr
+func (cfg ClientConfig) hardwareAddress(macAddr []byte) ([]byte, error) {
+	if cfg.MAC.Mode == MACModeHardware {
+		return macAddr, nil
+	}
+	if htype := cfg.hardwareType(); htype != HardwareTypeEthernet && htype != HardwareTypeIEEE802 {
+		return nil, fmt.Errorf("cannot generate a MAC address for %s", LookupHardwareType(htype).Name)
+	}
+
+	switch cfg.MAC.Mode {
+	case MACModeRandom:
+		return RandomMAC()
+	case MACModeStable:
+		return StableMAC(cfg.MAC.Secret, cfg.Interface, cfg.MAC.NetworkID)
+	default:
+		return nil, fmt.Errorf("unknown MAC mode %d", cfg.MAC.Mode)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRandomMAC(t *testing.T) {
+	first, err := RandomMAC()
+	if err != nil {
+		t.Fatalf("RandomMAC: %v", err)
+	}
+	second, err := RandomMAC()
+	if err != nil {
+		t.Fatalf("RandomMAC: %v", err)
+	}
+
+	for _, mac := range [][]byte{first, second} {
+		if len(mac) != 6 || !IsLocalUnicast(mac) {
+			t.Fatalf("%x is not a locally administered unicast address", mac)
+		}
+		if err := ValidateHardwareAddress(HardwareTypeEthernet, mac); err != nil {
+			t.Fatalf("%x: %v", mac, err)
+		}
+	}
+	if bytes.Equal(first, second) {
+		t.Fatalf("two random addresses are both %s", first)
+	}
+}
+
+func TestStableMAC(t *testing.T) {
+	secret := []byte("0123456789abcdef")
+
+	home, err := StableMAC(secret, "wlan0", "HomeNet")
+	if err != nil {
+		t.Fatalf("StableMAC: %v", err)
+	}
+	if !IsLocalUnicast(home) {
+		t.Fatalf("%s is not a locally administered unicast address", home)
+	}
+
+	again, _ := StableMAC(secret, "wlan0", "HomeNet")
+	if !bytes.Equal(home, again) {
+		t.Fatalf("address changed between calls: %s, %s", home, again)
+	}
+
+	others := map[string][]string{
+		"network":   {"wlan0", "CoffeeShop"},
+		"interface": {"wlan1", "HomeNet"},
+		"boundary":  {"wlan0Home", "Net"},
+	}
+	for name, inputs := range others {
+		mac, _ := StableMAC(secret, inputs[0], inputs[1])
+		if bytes.Equal(mac, home) {
+			t.Errorf("%s: same address %s", name, mac)
+		}
+	}
+
+	otherSecret, _ := StableMAC([]byte("fedcba9876543210"), "wlan0", "HomeNet")
+	if bytes.Equal(otherSecret, home) {
+		t.Error("address does not depend on the secret")
+	}
+
+	if _, err := StableMAC([]byte("short"), "wlan0", "HomeNet"); err == nil {
+		t.Error("expected an error for a short secret")
+	}
+}
+
+func TestGeneratedMACUsedInMessages(t *testing.T) {
+	realMAC := []byte{0x00, 0x1b, 0x21, 0x3a, 0x4b, 0x5c}
+	cfg := ClientConfig{
+		Interface: "wlan0",
+		MTU:       1500,
+		MAC:       MACConfig{Mode: MACModeStable, Secret: []byte("0123456789abcdef"), NetworkID: "HomeNet"},
+	}
+	client, err := NewDHCPClientWithConfig(realMAC, cfg)
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	want, _ := StableMAC(cfg.MAC.Secret, "wlan0", "HomeNet")
+	msg := client.createDHCPDiscover()
+	if got := msg.ClientHardwareAddress[:6]; !bytes.Equal(got, want) {
+		t.Errorf("chaddr: got %x, want %s", got, want)
+	}
+	if got := msg.Options[OptionClientIdentifier]; !bytes.Equal(got, append([]byte{HardwareTypeEthernet}, want...)) {
+		t.Errorf("client identifier: got %x, want 01%x", got, want)
+	}
+
+	// Returning to a network gives back its address
+	if err := client.NetworkChanged("CoffeeShop"); err != nil {
+		t.Fatalf("NetworkChanged: %v", err)
+	}
+	if bytes.Equal(client.HardwareAddress(), want) {
+		t.Fatal("address did not change with the network")
+	}
+	if err := client.NetworkChanged("HomeNet"); err != nil {
+		t.Fatalf("NetworkChanged: %v", err)
+	}
+	if !bytes.Equal(client.HardwareAddress(), want) {
+		t.Fatalf("got %s back on the home network, want %s", client.HardwareAddress(), want)
+	}
+}
+
+func TestHardwareMACModeKeepsAddress(t *testing.T) {
+	realMAC := []byte{0x00, 0x1b, 0x21, 0x3a, 0x4b, 0x5c}
+	client, err := NewDHCPClientWithConfig(realMAC, ClientConfig{})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+	if err := client.NetworkChanged("HomeNet"); err != nil {
+		t.Fatalf("NetworkChanged: %v", err)
+	}
+	if !bytes.Equal(client.HardwareAddress(), realMAC) {
+		t.Fatalf("got %s, want the hardware address", client.HardwareAddress())
+	}
+}
+
+func TestStableMACAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mac-secret")
+	newClient := func() *DHCPClient {
+		t.Helper()
+		secret, err := LoadOrCreateMACSecret(path)
+		if err != nil {
+			t.Fatalf("LoadOrCreateMACSecret: %v", err)
+		}
+		// No hardware address is needed when the MAC is generated
+		client, err := NewDHCPClientWithConfig(nil, ClientConfig{
+			MAC: MACConfig{Mode: MACModeStable, Secret: secret, NetworkID: "office"},
+		})
+		if err != nil {
+			t.Fatalf("NewDHCPClientWithConfig: %v", err)
+		}
+		return client
+	}
+
+	first, second := newClient().HardwareAddress(), newClient().HardwareAddress()
+	if !bytes.Equal(first, second) {
+		t.Fatalf("stable address changed across restarts: %s, %s", first, second)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("secret file not private: %v %v", info, err)
+	}
+}
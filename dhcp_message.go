@@ -24,6 +24,10 @@ type DHCPMessage struct {
 	BootFileName          []byte          `json:"file"`    // 128 bytes
 	MagicCookie           uint32          `json:"magic"`   // 4 bytes
 	Options               map[byte][]byte `json:"options"` // DHCP options
+
+	// raw is the packet the message was decoded from, kept for checks
+	// such as authentication that cover the exact bytes received
+	raw []byte
 }
 
 // Serialize serializes the DHCPMessage into a byte slice with error handling.
@@ -115,8 +119,13 @@ func (m *DHCPMessage) Serialize() ([]byte, error) {
 				continue // Skip pad and end options
 			}
 
-			if len(value) > 255 {
-				return nil, fmt.Errorf("option %d too long: %d bytes", code, len(value))
+			// Values longer than 255 bytes are split across several
+			// instances of the option (RFC 3396)
+			for len(value) > 255 {
+				buf.WriteByte(code)
+				buf.WriteByte(255)
+				buf.Write(value[:255])
+				value = value[255:]
 			}
 
 			buf.WriteByte(code)
@@ -128,17 +137,23 @@ func (m *DHCPMessage) Serialize() ([]byte, error) {
 	// Add end option
 	buf.WriteByte(OptionEnd)
 
+	// Pad to the minimum BOOTP message size so BOOTP relays and servers
+	// accept the message (RFC 1542)
+	for buf.Len() < SizeMinimumBOOTPMessageLength {
+		buf.WriteByte(OptionPad)
+	}
+
 	return buf.Bytes(), nil
 }
 
 // Deserialize parses a DHCPMessage from a byte slice with error handling.
 func Deserialize(data []byte) (*DHCPMessage, error) {
-	if len(data) < SizeMinimumDHCPMessageLength {
-		return nil, fmt.Errorf("data too short for DHCP message: got %d bytes, want at least %d", len(data), SizeMinimumDHCPMessageLength)
+	if len(data) < SizeBOOTPHeader {
+		return nil, fmt.Errorf("data too short for DHCP message: got %d bytes, want at least %d", len(data), SizeBOOTPHeader)
 	}
 
 	buf := bytes.NewBuffer(data)
-	m := &DHCPMessage{}
+	m := &DHCPMessage{raw: append([]byte(nil), data...)}
 	read := func(data interface{}, field string) error {
 		if err := binary.Read(buf, binary.BigEndian, data); err != nil {
 			return fmt.Errorf("failed to read %s: %w", field, err)
@@ -205,11 +220,46 @@ func Deserialize(data []byte) (*DHCPMessage, error) {
 		return nil, fmt.Errorf("failed to read %s: %w (read %d bytes)", FieldBootFileName, err, n)
 	}
 
+	m.Options = make(map[byte][]byte)
+
+	// BOOTP replies (RFC 951) may omit the magic cookie or carry vendor
+	// data in another format, in which case there are no options to parse
+	if buf.Len() < 4 {
+		return m, nil
+	}
 	if err := read(&m.MagicCookie, FieldMagicCookie); err != nil {
 		return nil, err
 	}
+	if m.MagicCookie != DHCPMagicCookie {
+		return m, nil
+	}
 
-	m.Options = make(map[byte][]byte)
+	if err := parseOptionArea(m.Options, buf); err != nil {
+		return nil, err
+	}
+
+	// Option overload (RFC 2132 section 9.3) carries further options in
+	// the file and sname fields, which are then read in that order
+	// (RFC 3396 section 5)
+	if overload := m.Options[OptionOptionOverload]; len(overload) == 1 {
+		if overload[0]&0x01 != 0 {
+			if err := parseOptionArea(m.Options, bytes.NewBuffer(m.BootFileName)); err != nil {
+				return nil, fmt.Errorf("%s: %w", FieldBootFileName, err)
+			}
+		}
+		if overload[0]&0x02 != 0 {
+			if err := parseOptionArea(m.Options, bytes.NewBuffer(m.ServerHostName)); err != nil {
+				return nil, fmt.Errorf("%s: %w", FieldServerHostName, err)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// parseOptionArea reads options up to the end option, adding them to
+// options
+func parseOptionArea(options map[byte][]byte, buf *bytes.Buffer) error {
 	for {
 		code, err := buf.ReadByte()
 		if err != nil {
@@ -220,6 +270,10 @@ func Deserialize(data []byte) (*DHCPMessage, error) {
 			break
 		}
 
+		if code == OptionPad {
+			continue
+		}
+
 		length, err := buf.ReadByte()
 		if err != nil {
 			break
@@ -227,13 +281,14 @@ func Deserialize(data []byte) (*DHCPMessage, error) {
 
 		value := make([]byte, length)
 		if _, err := buf.Read(value); err != nil {
-			return nil, fmt.Errorf("failed to read option value: %w", err)
+			return fmt.Errorf("failed to read option value: %w", err)
 		}
 
-		m.Options[code] = value
+		// Repeated options are concatenated (RFC 3396)
+		options[code] = append(options[code], value...)
 	}
 
-	return m, nil
+	return nil
 }
 
 // String returns a human-readable representation of the DHCP message
@@ -252,7 +307,7 @@ func (m *DHCPMessage) String() string {
 	result.WriteString(fmt.Sprintf("  Your IP: %s\n", m.ipToString(m.YourIP)))
 	result.WriteString(fmt.Sprintf("  Next Server IP: %s\n", m.ipToString(m.NextServerIP)))
 	result.WriteString(fmt.Sprintf("  Relay Agent IP: %s\n", m.ipToString(m.RelayAgentIP)))
-	result.WriteString(fmt.Sprintf("  Client Hardware Address: %s\n", m.macToString(m.ClientHardwareAddress)))
+	result.WriteString(fmt.Sprintf("  Client Hardware Address: %s\n", m.hardwareAddressString()))
 	result.WriteString(fmt.Sprintf("  Server Host Name: '%s'\n", m.bytesToString(m.ServerHostName)))
 	result.WriteString(fmt.Sprintf("  Boot File Name: '%s'\n", m.bytesToString(m.BootFileName)))
 	result.WriteString(fmt.Sprintf("  Magic Cookie: 0x%08x\n", m.MagicCookie))
@@ -280,18 +335,7 @@ func (m *DHCPMessage) opCodeString() string {
 }
 
 func (m *DHCPMessage) hardwareTypeString() string {
-	switch m.HardwareType {
-	case 1:
-		return "Ethernet"
-	case 6:
-		return "IEEE 802"
-	case 15:
-		return "Frame Relay"
-	case 16:
-		return "Asynchronous Transfer Mode (ATM)"
-	default:
-		return "Unknown"
-	}
+	return LookupHardwareType(m.HardwareType).Name
 }
 
 func (m *DHCPMessage) ipToString(ip uint32) string {
@@ -305,12 +349,16 @@ func (m *DHCPMessage) ipToString(ip uint32) string {
 		byte(ip))
 }
 
-func (m *DHCPMessage) macToString(mac []byte) string {
-	if len(mac) < 6 {
-		return "Invalid MAC"
+// hardwareAddressString formats the first hlen bytes of chaddr
+func (m *DHCPMessage) hardwareAddressString() string {
+	length := int(m.HardwareAddressLength)
+	if length == 0 {
+		return "(none)"
+	}
+	if length > len(m.ClientHardwareAddress) {
+		return fmt.Sprintf("Invalid (hlen %d)", length)
 	}
-	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x",
-		mac[0], mac[1], mac[2], mac[3], mac[4], mac[5])
+	return hardwareAddrString(m.ClientHardwareAddress[:length])
 }
 
 func (m *DHCPMessage) bytesToString(data []byte) string {
@@ -323,133 +371,179 @@ func (m *DHCPMessage) bytesToString(data []byte) string {
 }
 
 func (m *DHCPMessage) optionCodeString(code byte) string {
-	switch code {
-	case 1:
-		return "Subnet Mask"
-	case 3:
-		return "Router"
-	case 6:
-		return "DNS Server"
-	case 15:
-		return "Domain Name"
-	case 31:
-		return "Perform Router Discovery"
-	case 33:
-		return "Static Route"
-	case 43:
-		return "Vendor-Specific Information"
-	case 44:
-		return "NetBIOS over TCP/IP Name Server"
-	case 46:
-		return "NetBIOS over TCP/IP Node Type"
-	case 47:
-		return "NetBIOS over TCP/IP Scope"
-	case 50:
-		return "Requested IP Address"
-	case 51:
-		return "IP Address Lease Time"
-	case 53:
-		return "DHCP Message Type"
-	case 54:
-		return "Server Identifier"
-	case 55:
-		return "Parameter Request List"
-	case 57:
-		return "Maximum DHCP Message Size"
-	case 58:
-		return "Renewal (T1) Time Value"
-	case 59:
-		return "Rebinding (T2) Time Value"
-	case 60:
-		return "Vendor Class Identifier"
-	case 61:
-		return "Client-identifier"
-	case 66:
-		return "TFTP Server Name"
-	case 67:
-		return "Bootfile Name"
-	case 119:
-		return "Domain Search"
-	case 121:
-		return "Classless Static Route"
-	case 125:
-		return "Vendor-Identifying Vendor-Specific Information"
-	case 249:
-		return "Private/Classless Static Route (Microsoft)"
-	case 252:
-		return "Private/Proxy autodiscovery"
-	default:
-		return fmt.Sprintf("Option %d", code)
+	if info := LookupOption(code); info.Name != "" {
+		return info.Name
 	}
+	return fmt.Sprintf("Option %d", code)
 }
 
 func (m *DHCPMessage) optionValueString(code byte, value []byte) string {
-	switch code {
-	case 53: // DHCP Message Type
+	switch LookupOption(code).Type {
+	case OptionTypeNone:
+		if len(value) == 0 {
+			return "Present"
+		}
+	case OptionTypeMessageType:
 		if len(value) > 0 {
-			switch value[0] {
-			case 1:
-				return "DHCPDISCOVER"
-			case 2:
-				return "DHCPOFFER"
-			case 3:
-				return "DHCPREQUEST"
-			case 4:
-				return "DHCPDECLINE"
-			case 5:
-				return "DHCPACK"
-			case 6:
-				return "DHCPNAK"
-			case 7:
-				return "DHCPRELEASE"
-			case 8:
-				return "DHCPINFORM"
-			default:
-				return fmt.Sprintf("Unknown (%d)", value[0])
-			}
+			return messageTypeString(value[0])
 		}
 		return "Empty"
-	case 1, 3, 6, 54: // IP addresses
+	case OptionTypeIP, OptionTypeIPList:
+		if len(value) > 0 && len(value)%4 == 0 {
+			var ips []string
+			for i := 0; i < len(value); i += 4 {
+				ips = append(ips, m.ipToString(binary.BigEndian.Uint32(value[i:i+4])))
+			}
+			return strings.Join(ips, ", ")
+		}
+	case OptionTypeIPPairs:
+		if len(value) > 0 && len(value)%8 == 0 {
+			var pairs []string
+			for i := 0; i < len(value); i += 8 {
+				pairs = append(pairs, fmt.Sprintf("%s/%s",
+					m.ipToString(binary.BigEndian.Uint32(value[i:i+4])),
+					m.ipToString(binary.BigEndian.Uint32(value[i+4:i+8]))))
+			}
+			return strings.Join(pairs, ", ")
+		}
+	case OptionTypeUint8:
+		if len(value) == 1 {
+			return fmt.Sprintf("%d", value[0])
+		}
+	case OptionTypeUint16:
+		if len(value) == 2 {
+			return fmt.Sprintf("%d", binary.BigEndian.Uint16(value))
+		}
+	case OptionTypeUint16List:
+		if len(value) > 0 && len(value)%2 == 0 {
+			var values []string
+			for i := 0; i < len(value); i += 2 {
+				values = append(values, fmt.Sprintf("%d", binary.BigEndian.Uint16(value[i:i+2])))
+			}
+			return strings.Join(values, ", ")
+		}
+	case OptionTypeUint32:
 		if len(value) == 4 {
-			ip := binary.BigEndian.Uint32(value)
-			return m.ipToString(ip)
-		} else if len(value) == 8 {
-			ip1 := binary.BigEndian.Uint32(value[:4])
-			ip2 := binary.BigEndian.Uint32(value[4:])
-			return fmt.Sprintf("%s, %s", m.ipToString(ip1), m.ipToString(ip2))
+			return fmt.Sprintf("%d", binary.BigEndian.Uint32(value))
 		}
-
-		return fmt.Sprintf("%v", value)
-	case 51, 58, 59: // Time values
+	case OptionTypeInt32:
 		if len(value) == 4 {
-			time := binary.BigEndian.Uint32(value)
-			return fmt.Sprintf("%d seconds", time)
+			return fmt.Sprintf("%d", int32(binary.BigEndian.Uint32(value)))
 		}
-		return fmt.Sprintf("%v", value)
-	case 61: // Client-identifier
-		if len(value) > 1 {
-			hwType := value[0]
-			hwAddr := value[1:]
-			return fmt.Sprintf("Type %d: %s", hwType, m.macToString(hwAddr))
+	case OptionTypeDuration:
+		if len(value) == 4 {
+			return fmt.Sprintf("%d seconds", binary.BigEndian.Uint32(value))
+		}
+	case OptionTypeBool:
+		if len(value) == 1 {
+			return fmt.Sprintf("%t", value[0] != 0)
+		}
+	case OptionTypeString:
+		if len(value) > 0 {
+			return fmt.Sprintf("'%s'", m.bytesToString(value))
 		}
-		return fmt.Sprintf("%v", value)
-	case 55: // Parameter Request List
+	case OptionTypeCodeList:
 		var params []string
 		for _, param := range value {
 			params = append(params, m.optionCodeString(param))
 		}
 		return strings.Join(params, ", ")
-	case 125: // Vendor-Identifying Vendor-Specific Information
-		return fmt.Sprintf("'%s'", string(value))
-	default:
-		if len(value) == 0 {
-			return "Empty"
+	case OptionTypeClientID:
+		if len(value) > 1 {
+			return clientIDString(value)
+		}
+	case OptionTypeDomainList:
+		if domains, err := DecodeDomainSearch(value); err == nil && len(domains) > 0 {
+			return strings.Join(domains, ", ")
+		}
+	case OptionTypeVendorSpecific:
+		vendorClass := m.bytesToString(m.Options[OptionVendorClassIdentifier])
+		if info, err := DecodeVendorSpecific(vendorClass, value); err == nil {
+			return info.String()
+		}
+	case OptionTypeVIVendorClass:
+		if classes, err := DecodeVIVendorClass(value); err == nil && len(classes) > 0 {
+			var parts []string
+			for _, class := range classes {
+				parts = append(parts, class.String())
+			}
+			return strings.Join(parts, "; ")
 		}
-		// Try to convert to string if it looks like text
-		if isPrintable(value) {
-			return fmt.Sprintf("'%s'", string(value))
+	case OptionTypeVIVendorInfo:
+		if infos, err := DecodeVIVendorInfo(value); err == nil && len(infos) > 0 {
+			var parts []string
+			for _, info := range infos {
+				parts = append(parts, info.Decode().String())
+			}
+			return strings.Join(parts, "; ")
+		}
+	case OptionTypeClientFQDN:
+		if fqdn, err := DecodeClientFQDN(value); err == nil {
+			return fqdn.String()
+		}
+	case OptionTypeUserClass:
+		if classes, err := DecodeUserClass(value); err == nil {
+			quoted := make([]string, len(classes))
+			for i, class := range classes {
+				quoted[i] = opaqueString(class)
+			}
+			return strings.Join(quoted, ", ")
+		}
+	case OptionTypeURIList:
+		if uris, err := DecodeSZTPRedirect(value); err == nil && len(uris) > 0 {
+			return strings.Join(uris, ", ")
+		}
+	case OptionTypeAuthentication:
+		if auth, err := DecodeAuthentication(value); err == nil {
+			return auth.String()
+		}
+	case OptionTypeRelayAgentInfo:
+		if info, err := DecodeRelayAgentInfo(value); err == nil {
+			return info.String()
+		}
+	case OptionTypeClasslessRoutes:
+		if routes, err := DecodeClasslessRoutes(value); err == nil && len(routes) > 0 {
+			var parts []string
+			for _, route := range routes {
+				parts = append(parts, route.String())
+			}
+			return strings.Join(parts, ", ")
 		}
-		return fmt.Sprintf("%v", value)
+	}
+
+	if len(value) == 0 {
+		return "Empty"
+	}
+	// Try to convert to string if it looks like text
+	if isPrintable(value) {
+		return fmt.Sprintf("'%s'", string(value))
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// messageTypeString returns the name of a DHCP message type
+func messageTypeString(msgType byte) string {
+	switch msgType {
+	case DHCPDiscover:
+		return "DHCPDISCOVER"
+	case DHCPOffer:
+		return "DHCPOFFER"
+	case DHCPRequest:
+		return "DHCPREQUEST"
+	case DHCPDecline:
+		return "DHCPDECLINE"
+	case DHCPAck:
+		return "DHCPACK"
+	case DHCPNak:
+		return "DHCPNAK"
+	case DHCPRelease:
+		return "DHCPRELEASE"
+	case DHCPInform:
+		return "DHCPINFORM"
+	case DHCPForceRenew:
+		return "DHCPFORCERENEW"
+	default:
+		return fmt.Sprintf("Unknown (%d)", msgType)
 	}
 }
 
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+)
+
+// Message size limits (RFC 2131 section 2, RFC 2132 sections 5.1 and 9.10)
+const (
+	// MinMaxMessageSize is the smallest maximum message size a client may
+	// advertise, and the size every DHCP participant must accept
+	MinMaxMessageSize = 576
+	// MinMTU is the smallest valid interface MTU
+	MinMTU = 68
+	// DefaultMTU is used when the interface MTU is not known
+	DefaultMTU = 1500
+)
+
+// ConfigApplier applies settings from a lease to the system
+type ConfigApplier interface {
+	// SetMTU sets the interface MTU from option 26
+	SetMTU(mtu int) error
+}
+
+// IPCommandApplier applies lease settings with the ip(8) command
+type IPCommandApplier struct {
+	Interface string
+}
+
+// SetMTU runs "ip link set dev <interface> mtu <mtu>"
+func (a *IPCommandApplier) SetMTU(mtu int) error {
+	output, err := exec.Command("ip", "link", "set", "dev", a.Interface, "mtu", strconv.Itoa(mtu)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to set MTU of %s: %w: %s", a.Interface, err, output)
+	}
+	return nil
+}
+
+// interfaceMTU returns the configured MTU, the MTU of the configured
+// interface, or DefaultMTU
+func (cfg ClientConfig) interfaceMTU() (int, error) {
+	if cfg.MTU != 0 {
+		return cfg.MTU, nil
+	}
+	if cfg.Interface != "" {
+		iface, err := net.InterfaceByName(cfg.Interface)
+		if err != nil {
+			return 0, fmt.Errorf("failed to look up interface MTU: %w", err)
+		}
+		return iface.MTU, nil
+	}
+	return DefaultMTU, nil
+}
+
+// maxMessageSize returns the option 57 value for the interface MTU. Like
+// the MTU it counts the IP and UDP headers.
+func (cfg ClientConfig) maxMessageSize() (int, error) {
+	mtu, err := cfg.interfaceMTU()
+	if err != nil {
+		return 0, err
+	}
+	if mtu < MinMTU {
+		return 0, fmt.Errorf("invalid MTU %d", mtu)
+	}
+	return min(max(mtu, MinMaxMessageSize), 0xffff), nil
+}
+
+// addMaxMessageSize advertises the maximum message size (option 57). An
+// anonymous client does not send it, as the MTU helps fingerprint it.
+func (cfg ClientConfig) addMaxMessageSize(options map[byte][]byte) error {
+	size, err := cfg.maxMessageSize()
+	if err != nil {
+		return err
+	}
+	if !cfg.Anonymous {
+		options[OptionMaximumMessageSize] = binary.BigEndian.AppendUint16(nil, uint16(size))
+	}
+	return nil
+}
+
+// receiveBufferSize returns the size of the buffer replies are read into,
+// large enough for any message the client advertised it accepts
+func (c *DHCPClient) receiveBufferSize() int {
+	return max(c.maxMessageSize, DefaultMTU)
+}
+
+// applyMTU passes the server-provided MTU to the configuration applier
+func (c *DHCPClient) applyMTU() error {
+	if c.config.Applier == nil || c.lease.MTU == 0 {
+		return nil
+	}
+
+	fmt.Printf("Setting interface MTU to %d\n", c.lease.MTU)
+	return c.config.Applier.SetMTU(c.lease.MTU)
+}
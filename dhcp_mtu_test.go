@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordingApplier struct {
+	mtu int
+}
+
+func (a *recordingApplier) SetMTU(mtu int) error {
+	a.mtu = mtu
+	return nil
+}
+
+func TestMaxMessageSizeOption(t *testing.T) {
+	tests := []struct {
+		mtu  int
+		want uint16
+	}{
+		{0, DefaultMTU},
+		{9000, 9000},
+		{300, MinMaxMessageSize},
+	}
+	for _, tt := range tests {
+		client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{MTU: tt.mtu})
+		if err != nil {
+			t.Fatalf("MTU %d: %v", tt.mtu, err)
+		}
+		value := client.createDHCPDiscover().Options[OptionMaximumMessageSize]
+		if len(value) != 2 || binary.BigEndian.Uint16(value) != tt.want {
+			t.Errorf("MTU %d: option 57 = %v, want %d", tt.mtu, value, tt.want)
+		}
+		if client.receiveBufferSize() < int(tt.want) {
+			t.Errorf("MTU %d: receive buffer of %d bytes is smaller than advertised", tt.mtu, client.receiveBufferSize())
+		}
+	}
+
+	if _, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{MTU: 40}); err == nil {
+		t.Fatal("expected error for MTU below 68")
+	}
+}
+
+func TestApplyServerMTU(t *testing.T) {
+	lease, err := NewLease(&DHCPMessage{Options: map[byte][]byte{OptionInterfaceMTU: {0x05, 0xdc}}})
+	if err != nil {
+		t.Fatalf("NewLease: %v", err)
+	}
+	if lease.MTU != 1500 {
+		t.Fatalf("MTU = %d", lease.MTU)
+	}
+
+	applier := &recordingApplier{}
+	client := &DHCPClient{lease: lease, config: ClientConfig{Applier: applier}}
+	if err := client.applyMTU(); err != nil {
+		t.Fatalf("applyMTU: %v", err)
+	}
+	if applier.mtu != 1500 {
+		t.Fatalf("applier got MTU %d", applier.mtu)
+	}
+
+	if _, err := NewLease(&DHCPMessage{Options: map[byte][]byte{OptionInterfaceMTU: {0, 20}}}); err == nil {
+		t.Fatal("expected error for MTU below 68")
+	}
+}
+
+func TestReceiveLargeReply(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer conn.Close()
+
+	client := &DHCPClient{receiveSocket: conn, maxMessageSize: 9000}
+
+	// A reply larger than the old fixed 1024-byte buffer
+	msg := &DHCPMessage{
+		OpCode:                BootReply,
+		ClientHardwareAddress: make([]byte, SizeClientHardwareAddress),
+		ServerHostName:        make([]byte, SizeServerHostName),
+		BootFileName:          make([]byte, SizeBootFileName),
+		MagicCookie:           DHCPMagicCookie,
+		Options: map[byte][]byte{
+			OptionDHCPMessageType: {DHCPAck},
+			OptionDomainName:      []byte(strings.Repeat("a", 2000)),
+		},
+	}
+	data, err := msg.Serialize()
+	if err != nil {
+		t.Fatalf("serialize: %v", err)
+	}
+	if _, err := conn.WriteToUDP(data, conn.LocalAddr().(*net.UDPAddr)); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	reply, err := client.waitForMessage(DHCPAck, 3*time.Second)
+	if err != nil {
+		t.Fatalf("waitForMessage: %v", err)
+	}
+	if len(reply.Options[OptionDomainName]) != 2000 {
+		t.Fatalf("option truncated to %d bytes", len(reply.Options[OptionDomainName]))
+	}
+}
@@ -0,0 +1,263 @@
+// Code generated by gen_options.go from iana/options.csv; DO NOT EDIT.
+
+package main
+
+// optionTable describes every DHCP option code in the IANA registry.
+var optionTable = [256]OptionInfo{
+	0:   {Code: 0, Name: "Pad", Reference: "RFC 2132", Type: OptionTypeNone},
+	1:   {Code: 1, Name: "Subnet Mask", Reference: "RFC 2132", Type: OptionTypeIP},
+	2:   {Code: 2, Name: "Time Offset", Reference: "RFC 2132", Type: OptionTypeInt32},
+	3:   {Code: 3, Name: "Router", Reference: "RFC 2132", Type: OptionTypeIPList},
+	4:   {Code: 4, Name: "Time Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	5:   {Code: 5, Name: "Name Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	6:   {Code: 6, Name: "Domain Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	7:   {Code: 7, Name: "Log Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	8:   {Code: 8, Name: "Quotes Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	9:   {Code: 9, Name: "LPR Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	10:  {Code: 10, Name: "Impress Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	11:  {Code: 11, Name: "RLP Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	12:  {Code: 12, Name: "Hostname", Reference: "RFC 2132", Type: OptionTypeString},
+	13:  {Code: 13, Name: "Boot File Size", Reference: "RFC 2132", Type: OptionTypeUint16},
+	14:  {Code: 14, Name: "Merit Dump File", Reference: "RFC 2132", Type: OptionTypeString},
+	15:  {Code: 15, Name: "Domain Name", Reference: "RFC 2132", Type: OptionTypeString},
+	16:  {Code: 16, Name: "Swap Server", Reference: "RFC 2132", Type: OptionTypeIP},
+	17:  {Code: 17, Name: "Root Path", Reference: "RFC 2132", Type: OptionTypeString},
+	18:  {Code: 18, Name: "Extension File", Reference: "RFC 2132", Type: OptionTypeString},
+	19:  {Code: 19, Name: "Forward On/Off", Reference: "RFC 2132", Type: OptionTypeBool},
+	20:  {Code: 20, Name: "SrcRte On/Off", Reference: "RFC 2132", Type: OptionTypeBool},
+	21:  {Code: 21, Name: "Policy Filter", Reference: "RFC 2132", Type: OptionTypeIPPairs},
+	22:  {Code: 22, Name: "Max DG Assembly", Reference: "RFC 2132", Type: OptionTypeUint16},
+	23:  {Code: 23, Name: "Default IP TTL", Reference: "RFC 2132", Type: OptionTypeUint8},
+	24:  {Code: 24, Name: "MTU Timeout", Reference: "RFC 2132", Type: OptionTypeDuration},
+	25:  {Code: 25, Name: "MTU Plateau", Reference: "RFC 2132", Type: OptionTypeUint16List},
+	26:  {Code: 26, Name: "MTU Interface", Reference: "RFC 2132", Type: OptionTypeUint16},
+	27:  {Code: 27, Name: "MTU Subnet", Reference: "RFC 2132", Type: OptionTypeBool},
+	28:  {Code: 28, Name: "Broadcast Address", Reference: "RFC 2132", Type: OptionTypeIP},
+	29:  {Code: 29, Name: "Mask Discovery", Reference: "RFC 2132", Type: OptionTypeBool},
+	30:  {Code: 30, Name: "Mask Supplier", Reference: "RFC 2132", Type: OptionTypeBool},
+	31:  {Code: 31, Name: "Router Discovery", Reference: "RFC 2132", Type: OptionTypeBool},
+	32:  {Code: 32, Name: "Router Request", Reference: "RFC 2132", Type: OptionTypeIP},
+	33:  {Code: 33, Name: "Static Route", Reference: "RFC 2132", Type: OptionTypeIPPairs},
+	34:  {Code: 34, Name: "Trailers", Reference: "RFC 2132", Type: OptionTypeBool},
+	35:  {Code: 35, Name: "ARP Timeout", Reference: "RFC 2132", Type: OptionTypeDuration},
+	36:  {Code: 36, Name: "Ethernet", Reference: "RFC 2132", Type: OptionTypeBool},
+	37:  {Code: 37, Name: "Default TCP TTL", Reference: "RFC 2132", Type: OptionTypeUint8},
+	38:  {Code: 38, Name: "Keepalive Time", Reference: "RFC 2132", Type: OptionTypeDuration},
+	39:  {Code: 39, Name: "Keepalive Data", Reference: "RFC 2132", Type: OptionTypeBool},
+	40:  {Code: 40, Name: "NIS Domain", Reference: "RFC 2132", Type: OptionTypeString},
+	41:  {Code: 41, Name: "NIS Servers", Reference: "RFC 2132", Type: OptionTypeIPList},
+	42:  {Code: 42, Name: "NTP Servers", Reference: "RFC 2132", Type: OptionTypeIPList},
+	43:  {Code: 43, Name: "Vendor Specific", Reference: "RFC 2132", Type: OptionTypeVendorSpecific},
+	44:  {Code: 44, Name: "NETBIOS Name Srv", Reference: "RFC 2132", Type: OptionTypeIPList},
+	45:  {Code: 45, Name: "NETBIOS Dist Srv", Reference: "RFC 2132", Type: OptionTypeIPList},
+	46:  {Code: 46, Name: "NETBIOS Node Type", Reference: "RFC 2132", Type: OptionTypeUint8},
+	47:  {Code: 47, Name: "NETBIOS Scope", Reference: "RFC 2132", Type: OptionTypeString},
+	48:  {Code: 48, Name: "X Window Font", Reference: "RFC 2132", Type: OptionTypeIPList},
+	49:  {Code: 49, Name: "X Window Manager", Reference: "RFC 2132", Type: OptionTypeIPList},
+	50:  {Code: 50, Name: "Address Request", Reference: "RFC 2132", Type: OptionTypeIP},
+	51:  {Code: 51, Name: "Address Time", Reference: "RFC 2132", Type: OptionTypeDuration},
+	52:  {Code: 52, Name: "Overload", Reference: "RFC 2132", Type: OptionTypeUint8},
+	53:  {Code: 53, Name: "DHCP Msg Type", Reference: "RFC 2132", Type: OptionTypeMessageType},
+	54:  {Code: 54, Name: "DHCP Server Id", Reference: "RFC 2132", Type: OptionTypeIP},
+	55:  {Code: 55, Name: "Parameter List", Reference: "RFC 2132", Type: OptionTypeCodeList},
+	56:  {Code: 56, Name: "DHCP Message", Reference: "RFC 2132", Type: OptionTypeString},
+	57:  {Code: 57, Name: "DHCP Max Msg Size", Reference: "RFC 2132", Type: OptionTypeUint16},
+	58:  {Code: 58, Name: "Renewal Time", Reference: "RFC 2132", Type: OptionTypeDuration},
+	59:  {Code: 59, Name: "Rebinding Time", Reference: "RFC 2132", Type: OptionTypeDuration},
+	60:  {Code: 60, Name: "Class Id", Reference: "RFC 2132", Type: OptionTypeString},
+	61:  {Code: 61, Name: "Client Id", Reference: "RFC 2132", Type: OptionTypeClientID},
+	62:  {Code: 62, Name: "NetWare/IP Domain", Reference: "RFC 2242", Type: OptionTypeString},
+	63:  {Code: 63, Name: "NetWare/IP Option", Reference: "RFC 2242", Type: OptionTypeBytes},
+	64:  {Code: 64, Name: "NIS-Domain-Name", Reference: "RFC 2132", Type: OptionTypeString},
+	65:  {Code: 65, Name: "NIS-Server-Addr", Reference: "RFC 2132", Type: OptionTypeIPList},
+	66:  {Code: 66, Name: "Server-Name", Reference: "RFC 2132", Type: OptionTypeString},
+	67:  {Code: 67, Name: "Bootfile-Name", Reference: "RFC 2132", Type: OptionTypeString},
+	68:  {Code: 68, Name: "Home-Agent-Addrs", Reference: "RFC 2132", Type: OptionTypeIPList},
+	69:  {Code: 69, Name: "SMTP-Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	70:  {Code: 70, Name: "POP3-Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	71:  {Code: 71, Name: "NNTP-Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	72:  {Code: 72, Name: "WWW-Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	73:  {Code: 73, Name: "Finger-Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	74:  {Code: 74, Name: "IRC-Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	75:  {Code: 75, Name: "StreetTalk-Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	76:  {Code: 76, Name: "STDA-Server", Reference: "RFC 2132", Type: OptionTypeIPList},
+	77:  {Code: 77, Name: "User-Class", Reference: "RFC 3004", Type: OptionTypeUserClass},
+	78:  {Code: 78, Name: "Directory Agent", Reference: "RFC 2610", Type: OptionTypeBytes},
+	79:  {Code: 79, Name: "Service Scope", Reference: "RFC 2610", Type: OptionTypeBytes},
+	80:  {Code: 80, Name: "Rapid Commit", Reference: "RFC 4039", Type: OptionTypeNone},
+	81:  {Code: 81, Name: "Client FQDN", Reference: "RFC 4702", Type: OptionTypeClientFQDN},
+	82:  {Code: 82, Name: "Relay Agent Information", Reference: "RFC 3046", Type: OptionTypeRelayAgentInfo},
+	83:  {Code: 83, Name: "iSNS", Reference: "RFC 4174", Type: OptionTypeBytes},
+	84:  {Code: 84, Name: "REMOVED/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	85:  {Code: 85, Name: "NDS Servers", Reference: "RFC 2241", Type: OptionTypeIPList},
+	86:  {Code: 86, Name: "NDS Tree Name", Reference: "RFC 2241", Type: OptionTypeString},
+	87:  {Code: 87, Name: "NDS Context", Reference: "RFC 2241", Type: OptionTypeString},
+	88:  {Code: 88, Name: "BCMCS Controller Domain Name list", Reference: "RFC 4280", Type: OptionTypeDomainList},
+	89:  {Code: 89, Name: "BCMCS Controller IPv4 address option", Reference: "RFC 4280", Type: OptionTypeIPList},
+	90:  {Code: 90, Name: "Authentication", Reference: "RFC 3118", Type: OptionTypeAuthentication},
+	91:  {Code: 91, Name: "client-last-transaction-time option", Reference: "RFC 4388", Type: OptionTypeUint32},
+	92:  {Code: 92, Name: "associated-ip option", Reference: "RFC 4388", Type: OptionTypeIPList},
+	93:  {Code: 93, Name: "Client System", Reference: "RFC 4578", Type: OptionTypeUint16List},
+	94:  {Code: 94, Name: "Client NDI", Reference: "RFC 4578", Type: OptionTypeBytes},
+	95:  {Code: 95, Name: "LDAP", Reference: "RFC 3679", Type: OptionTypeBytes},
+	96:  {Code: 96, Name: "REMOVED/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	97:  {Code: 97, Name: "UUID/GUID", Reference: "RFC 4578", Type: OptionTypeBytes},
+	98:  {Code: 98, Name: "User-Auth", Reference: "RFC 2485", Type: OptionTypeBytes},
+	99:  {Code: 99, Name: "GEOCONF_CIVIC", Reference: "RFC 4776", Type: OptionTypeBytes},
+	100: {Code: 100, Name: "PCode", Reference: "RFC 4833", Type: OptionTypeString},
+	101: {Code: 101, Name: "TCode", Reference: "RFC 4833", Type: OptionTypeString},
+	102: {Code: 102, Name: "REMOVED/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	103: {Code: 103, Name: "REMOVED/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	104: {Code: 104, Name: "REMOVED/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	105: {Code: 105, Name: "REMOVED/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	106: {Code: 106, Name: "REMOVED/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	107: {Code: 107, Name: "REMOVED/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	108: {Code: 108, Name: "IPv6-Only Preferred", Reference: "RFC 8925", Type: OptionTypeDuration},
+	109: {Code: 109, Name: "OPTION_DHCP4O6_S46_SADDR", Reference: "RFC 8539", Type: OptionTypeBytes},
+	110: {Code: 110, Name: "REMOVED/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	111: {Code: 111, Name: "Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	112: {Code: 112, Name: "Netinfo Address", Reference: "RFC 3679", Type: OptionTypeIPList},
+	113: {Code: 113, Name: "Netinfo Tag", Reference: "RFC 3679", Type: OptionTypeString},
+	114: {Code: 114, Name: "DHCP Captive-Portal", Reference: "RFC 8910", Type: OptionTypeString},
+	115: {Code: 115, Name: "REMOVED/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	116: {Code: 116, Name: "Auto-Config", Reference: "RFC 2563", Type: OptionTypeBool},
+	117: {Code: 117, Name: "Name Service Search", Reference: "RFC 2937", Type: OptionTypeUint16List},
+	118: {Code: 118, Name: "Subnet Selection Option", Reference: "RFC 3011", Type: OptionTypeIP},
+	119: {Code: 119, Name: "Domain Search", Reference: "RFC 3397", Type: OptionTypeDomainList},
+	120: {Code: 120, Name: "SIP Servers DHCP Option", Reference: "RFC 3361", Type: OptionTypeBytes},
+	121: {Code: 121, Name: "Classless Static Route Option", Reference: "RFC 3442", Type: OptionTypeClasslessRoutes},
+	122: {Code: 122, Name: "CCC", Reference: "RFC 3495", Type: OptionTypeBytes},
+	123: {Code: 123, Name: "GeoConf Option", Reference: "RFC 6225", Type: OptionTypeBytes},
+	124: {Code: 124, Name: "V-I Vendor Class", Reference: "RFC 3925", Type: OptionTypeVIVendorClass},
+	125: {Code: 125, Name: "V-I Vendor-Specific Information", Reference: "RFC 3925", Type: OptionTypeVIVendorInfo},
+	126: {Code: 126, Name: "Removed/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	127: {Code: 127, Name: "Removed/Unassigned", Reference: "RFC 3679", Type: OptionTypeBytes},
+	128: {Code: 128, Name: "PXE - undefined (vendor specific)", Reference: "RFC 4578", Type: OptionTypeBytes},
+	129: {Code: 129, Name: "PXE - undefined (vendor specific)", Reference: "RFC 4578", Type: OptionTypeBytes},
+	130: {Code: 130, Name: "PXE - undefined (vendor specific)", Reference: "RFC 4578", Type: OptionTypeBytes},
+	131: {Code: 131, Name: "PXE - undefined (vendor specific)", Reference: "RFC 4578", Type: OptionTypeBytes},
+	132: {Code: 132, Name: "PXE - undefined (vendor specific)", Reference: "RFC 4578", Type: OptionTypeBytes},
+	133: {Code: 133, Name: "PXE - undefined (vendor specific)", Reference: "RFC 4578", Type: OptionTypeBytes},
+	134: {Code: 134, Name: "PXE - undefined (vendor specific)", Reference: "RFC 4578", Type: OptionTypeBytes},
+	135: {Code: 135, Name: "PXE - undefined (vendor specific)", Reference: "RFC 4578", Type: OptionTypeBytes},
+	136: {Code: 136, Name: "OPTION_PANA_AGENT", Reference: "RFC 5192", Type: OptionTypeBytes},
+	137: {Code: 137, Name: "OPTION_V4_LOST", Reference: "RFC 5223", Type: OptionTypeBytes},
+	138: {Code: 138, Name: "OPTION_CAPWAP_AC_V4", Reference: "RFC 5417", Type: OptionTypeIPList},
+	139: {Code: 139, Name: "OPTION-IPv4_Address-MoS", Reference: "RFC 5678", Type: OptionTypeBytes},
+	140: {Code: 140, Name: "OPTION-IPv4_FQDN-MoS", Reference: "RFC 5678", Type: OptionTypeBytes},
+	141: {Code: 141, Name: "SIP UA Configuration Service Domains", Reference: "RFC 6011", Type: OptionTypeDomainList},
+	142: {Code: 142, Name: "OPTION-IPv4_Address-ANDSF", Reference: "RFC 6153", Type: OptionTypeBytes},
+	143: {Code: 143, Name: "OPTION_V4_SZTP_REDIRECT", Reference: "RFC 8572", Type: OptionTypeURIList},
+	144: {Code: 144, Name: "GeoLoc", Reference: "RFC 6225", Type: OptionTypeBytes},
+	145: {Code: 145, Name: "FORCERENEW_NONCE_CAPABLE", Reference: "RFC 6704", Type: OptionTypeBytes},
+	146: {Code: 146, Name: "RDNSS Selection", Reference: "RFC 6731", Type: OptionTypeBytes},
+	147: {Code: 147, Name: "OPTION_V4_DOTS_RI", Reference: "RFC 8973", Type: OptionTypeBytes},
+	148: {Code: 148, Name: "OPTION_V4_DOTS_ADDRESS", Reference: "RFC 8973", Type: OptionTypeBytes},
+	149: {Code: 149, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	150: {Code: 150, Name: "TFTP server address", Reference: "RFC 5859", Type: OptionTypeIPList},
+	151: {Code: 151, Name: "status-code", Reference: "RFC 6926", Type: OptionTypeBytes},
+	152: {Code: 152, Name: "base-time", Reference: "RFC 6926", Type: OptionTypeUint32},
+	153: {Code: 153, Name: "start-time-of-state", Reference: "RFC 6926", Type: OptionTypeDuration},
+	154: {Code: 154, Name: "query-start-time", Reference: "RFC 6926", Type: OptionTypeUint32},
+	155: {Code: 155, Name: "query-end-time", Reference: "RFC 6926", Type: OptionTypeUint32},
+	156: {Code: 156, Name: "dhcp-state", Reference: "RFC 6926", Type: OptionTypeUint8},
+	157: {Code: 157, Name: "data-source", Reference: "RFC 6926", Type: OptionTypeUint8},
+	158: {Code: 158, Name: "OPTION_V4_PCP_SERVER", Reference: "RFC 7291", Type: OptionTypeBytes},
+	159: {Code: 159, Name: "OPTION_V4_PORTPARAMS", Reference: "RFC 7618", Type: OptionTypeBytes},
+	160: {Code: 160, Name: "Unassigned", Reference: "RFC 7710, RFC 8910", Type: OptionTypeBytes},
+	161: {Code: 161, Name: "OPTION_MUD_URL_V4", Reference: "RFC 8520", Type: OptionTypeString},
+	162: {Code: 162, Name: "OPTION_V4_DNR", Reference: "RFC 9463", Type: OptionTypeBytes},
+	163: {Code: 163, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	164: {Code: 164, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	165: {Code: 165, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	166: {Code: 166, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	167: {Code: 167, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	168: {Code: 168, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	169: {Code: 169, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	170: {Code: 170, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	171: {Code: 171, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	172: {Code: 172, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	173: {Code: 173, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	174: {Code: 174, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	175: {Code: 175, Name: "Etherboot (Tentatively Assigned - 2005-06-23)", Reference: "", Type: OptionTypeBytes},
+	176: {Code: 176, Name: "IP Telephone (Tentatively Assigned - 2005-06-23)", Reference: "", Type: OptionTypeBytes},
+	177: {Code: 177, Name: "PacketCable and CableHome (replaced by 122)", Reference: "", Type: OptionTypeBytes},
+	178: {Code: 178, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	179: {Code: 179, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	180: {Code: 180, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	181: {Code: 181, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	182: {Code: 182, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	183: {Code: 183, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	184: {Code: 184, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	185: {Code: 185, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	186: {Code: 186, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	187: {Code: 187, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	188: {Code: 188, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	189: {Code: 189, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	190: {Code: 190, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	191: {Code: 191, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	192: {Code: 192, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	193: {Code: 193, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	194: {Code: 194, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	195: {Code: 195, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	196: {Code: 196, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	197: {Code: 197, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	198: {Code: 198, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	199: {Code: 199, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	200: {Code: 200, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	201: {Code: 201, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	202: {Code: 202, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	203: {Code: 203, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	204: {Code: 204, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	205: {Code: 205, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	206: {Code: 206, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	207: {Code: 207, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	208: {Code: 208, Name: "PXELINUX Magic", Reference: "RFC 5071", Type: OptionTypeBytes},
+	209: {Code: 209, Name: "Configuration File", Reference: "RFC 5071", Type: OptionTypeString},
+	210: {Code: 210, Name: "Path Prefix", Reference: "RFC 5071", Type: OptionTypeString},
+	211: {Code: 211, Name: "Reboot Time", Reference: "RFC 5071", Type: OptionTypeDuration},
+	212: {Code: 212, Name: "OPTION_6RD", Reference: "RFC 5969", Type: OptionTypeBytes},
+	213: {Code: 213, Name: "OPTION_V4_ACCESS_DOMAIN", Reference: "RFC 5986", Type: OptionTypeBytes},
+	214: {Code: 214, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	215: {Code: 215, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	216: {Code: 216, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	217: {Code: 217, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	218: {Code: 218, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	219: {Code: 219, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	220: {Code: 220, Name: "Subnet Allocation Option", Reference: "RFC 6656", Type: OptionTypeBytes},
+	221: {Code: 221, Name: "Virtual Subnet Selection (VSS) Option", Reference: "RFC 6607", Type: OptionTypeBytes},
+	222: {Code: 222, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	223: {Code: 223, Name: "Unassigned", Reference: "", Type: OptionTypeBytes},
+	224: {Code: 224, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	225: {Code: 225, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	226: {Code: 226, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	227: {Code: 227, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	228: {Code: 228, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	229: {Code: 229, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	230: {Code: 230, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	231: {Code: 231, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	232: {Code: 232, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	233: {Code: 233, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	234: {Code: 234, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	235: {Code: 235, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	236: {Code: 236, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	237: {Code: 237, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	238: {Code: 238, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	239: {Code: 239, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	240: {Code: 240, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	241: {Code: 241, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	242: {Code: 242, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	243: {Code: 243, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	244: {Code: 244, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	245: {Code: 245, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	246: {Code: 246, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	247: {Code: 247, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	248: {Code: 248, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	249: {Code: 249, Name: "Private/Classless Static Route (Microsoft)", Reference: "", Type: OptionTypeClasslessRoutes},
+	250: {Code: 250, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	251: {Code: 251, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	252: {Code: 252, Name: "Private/Proxy autodiscovery", Reference: "", Type: OptionTypeString},
+	253: {Code: 253, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	254: {Code: 254, Name: "Reserved (Private Use)", Reference: "", Type: OptionTypeBytes},
+	255: {Code: 255, Name: "End", Reference: "RFC 2132", Type: OptionTypeNone},
+}
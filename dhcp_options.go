@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+//go:generate go run gen_options.go
+
+// OptionType describes how the value of a DHCP option is encoded
+type OptionType uint8
+
+// Option data types used by the generated option table
+const (
+	OptionTypeBytes           OptionType = iota // Opaque bytes
+	OptionTypeNone                              // No value (pad, end, flags)
+	OptionTypeIP                                // Single IPv4 address
+	OptionTypeIPList                            // List of IPv4 addresses
+	OptionTypeIPPairs                           // List of IPv4 address pairs
+	OptionTypeUint8                             // 8-bit unsigned integer
+	OptionTypeUint16                            // 16-bit unsigned integer
+	OptionTypeUint16List                        // List of 16-bit unsigned integers
+	OptionTypeUint32                            // 32-bit unsigned integer
+	OptionTypeInt32                             // 32-bit signed integer
+	OptionTypeDuration                          // 32-bit unsigned number of seconds
+	OptionTypeBool                              // Single byte, 0 or 1
+	OptionTypeString                            // NVT ASCII text
+	OptionTypeMessageType                       // DHCP message type
+	OptionTypeCodeList                          // List of option codes
+	OptionTypeClientID                          // Client identifier
+	OptionTypeClasslessRoutes                   // RFC 3442 classless static routes
+	OptionTypeDomainList                        // RFC 3397 compressed domain name list
+	OptionTypeRelayAgentInfo                    // RFC 3046 relay agent sub-options
+	OptionTypeVendorSpecific                    // Vendor sub-options keyed on option 60
+	OptionTypeVIVendorClass                     // RFC 3925 V-I vendor class
+	OptionTypeVIVendorInfo                      // RFC 3925 V-I vendor-specific information
+	OptionTypeClientFQDN                        // RFC 4702 client FQDN
+	OptionTypeAuthentication                    // RFC 3118 authentication
+	OptionTypeURIList                           // RFC 8572 length-prefixed URI list
+	OptionTypeUserClass                         // RFC 3004 user class list
+)
+
+// String returns the name of the option type
+func (t OptionType) String() string {
+	switch t {
+	case OptionTypeBytes:
+		return "bytes"
+	case OptionTypeNone:
+		return "none"
+	case OptionTypeIP:
+		return "ip"
+	case OptionTypeIPList:
+		return "ip-list"
+	case OptionTypeIPPairs:
+		return "ip-pairs"
+	case OptionTypeUint8:
+		return "uint8"
+	case OptionTypeUint16:
+		return "uint16"
+	case OptionTypeUint16List:
+		return "uint16-list"
+	case OptionTypeUint32:
+		return "uint32"
+	case OptionTypeInt32:
+		return "int32"
+	case OptionTypeDuration:
+		return "duration"
+	case OptionTypeBool:
+		return "bool"
+	case OptionTypeString:
+		return "string"
+	case OptionTypeMessageType:
+		return "message-type"
+	case OptionTypeCodeList:
+		return "code-list"
+	case OptionTypeClientID:
+		return "client-id"
+	case OptionTypeClasslessRoutes:
+		return "classless-routes"
+	case OptionTypeDomainList:
+		return "domain-list"
+	case OptionTypeRelayAgentInfo:
+		return "relay-agent-info"
+	case OptionTypeVendorSpecific:
+		return "vendor-specific"
+	case OptionTypeVIVendorClass:
+		return "vi-vendor-class"
+	case OptionTypeVIVendorInfo:
+		return "vi-vendor-info"
+	case OptionTypeClientFQDN:
+		return "client-fqdn"
+	case OptionTypeAuthentication:
+		return "authentication"
+	case OptionTypeURIList:
+		return "uri-list"
+	case OptionTypeUserClass:
+		return "user-class"
+	default:
+		return fmt.Sprintf("OptionType(%d)", uint8(t))
+	}
+}
+
+// OptionInfo describes a DHCP option code as registered with IANA
+type OptionInfo struct {
+	Code      byte
+	Name      string
+	Reference string
+	Type      OptionType
+}
+
+// LookupOption returns the registry entry for an option code
+func LookupOption(code byte) OptionInfo {
+	return optionTable[code]
+}
+
+// optionNames maps normalized option names to codes. Names shared by
+// several codes, such as "Unassigned", are left out.
+var optionNames = sync.OnceValue(func() map[string]byte {
+	names := make(map[string]byte)
+	ambiguous := make(map[string]bool)
+	for _, info := range optionTable {
+		name := normalizeOptionName(info.Name)
+		if _, exists := names[name]; exists {
+			ambiguous[name] = true
+		}
+		names[name] = info.Code
+	}
+	for name := range ambiguous {
+		delete(names, name)
+	}
+	return names
+})
+
+// normalizeOptionName lower-cases a name and joins its words with hyphens,
+// so "NTP Servers", "ntp-servers" and "ntp_servers" are the same option
+func normalizeOptionName(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "-")
+}
+
+// LookupOptionByName returns the registry entry for an option name. The
+// match ignores case and treats spaces, hyphens and underscores alike.
+func LookupOptionByName(name string) (OptionInfo, bool) {
+	code, exists := optionNames()[normalizeOptionName(name)]
+	if !exists {
+		return OptionInfo{}, false
+	}
+	return optionTable[code], true
+}
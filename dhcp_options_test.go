@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestOptionTableCoversEveryCode(t *testing.T) {
+	for code := 0; code < 256; code++ {
+		info := LookupOption(byte(code))
+		if int(info.Code) != code {
+			t.Fatalf("option %d: table entry has code %d", code, info.Code)
+		}
+		if info.Name == "" {
+			t.Fatalf("option %d has no name", code)
+		}
+	}
+}
+
+func TestOptionTableTypes(t *testing.T) {
+	tests := []struct {
+		code byte
+		typ  OptionType
+	}{
+		{1, OptionTypeIP},
+		{3, OptionTypeIPList},
+		{51, OptionTypeDuration},
+		{53, OptionTypeMessageType},
+		{55, OptionTypeCodeList},
+		{61, OptionTypeClientID},
+		{100, OptionTypeString},
+	}
+
+	for _, tt := range tests {
+		if got := LookupOption(tt.code).Type; got != tt.typ {
+			t.Errorf("option %d: got type %s, want %s", tt.code, got, tt.typ)
+		}
+	}
+}
+
+func TestOptionValueStringUsesTable(t *testing.T) {
+	m := &DHCPMessage{}
+
+	tests := []struct {
+		code  byte
+		value []byte
+		want  string
+	}{
+		{OptionDHCPMessageType, []byte{DHCPOffer}, "DHCPOFFER"},
+		{6, []byte{8, 8, 8, 8, 1, 1, 1, 1}, "8.8.8.8, 1.1.1.1"},
+		{26, []byte{0x05, 0xdc}, "1500"},
+		{51, []byte{0, 1, 0x51, 0x80}, "86400 seconds"},
+		{OptionParameterRequestList, []byte{1, 3}, "Subnet Mask, Router"},
+	}
+
+	for _, tt := range tests {
+		if got := m.optionValueString(tt.code, tt.value); got != tt.want {
+			t.Errorf("option %d: got %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
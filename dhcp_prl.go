@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// RequestProfile names a base set of options for the parameter request
+// list (option 55)
+type RequestProfile string
+
+// Parameter request list profiles
+const (
+	// RequestProfileMinimal asks only for what is needed to reach the
+	// network: mask, router, DNS servers and domain name
+	RequestProfileMinimal RequestProfile = "minimal"
+	// RequestProfileDesktop adds static routes, NetBIOS, domain search and
+	// proxy auto-configuration. It is the default.
+	RequestProfileDesktop RequestProfile = "desktop"
+	// RequestProfileServer adds the interface MTU, broadcast address and
+	// NTP servers
+	RequestProfileServer RequestProfile = "server"
+	// RequestProfileAnonymous is the short, common list the RFC 7844
+	// anonymity profile recommends, so the request does not fingerprint
+	// the client
+	RequestProfileAnonymous RequestProfile = "anonymous"
+)
+
+// requestProfiles holds the option codes of each profile in the order
+// they are requested
+var requestProfiles = map[RequestProfile][]byte{
+	RequestProfileMinimal:   {1, 3, 6, 15},
+	RequestProfileDesktop:   {1, 3, 6, 15, 31, 33, 43, 44, 46, 47, 119, 121, 249, 252},
+	RequestProfileServer:    {1, 3, 6, 15, 26, 28, 42, 119, 121},
+	RequestProfileAnonymous: {1, 3, 6, 15, 119, 121},
+}
+
+// ParseRequestOption resolves an option given by code ("42") or registry
+// name ("ntp-servers")
+func ParseRequestOption(s string) (byte, error) {
+	if code, err := strconv.ParseUint(s, 10, 8); err == nil {
+		return byte(code), nil
+	}
+	info, exists := LookupOptionByName(s)
+	if !exists {
+		return 0, fmt.Errorf("unknown option %q", s)
+	}
+	return info.Code, nil
+}
+
+// parameterRequestList builds option 55 from the profile, the requested
+// options and the options enabled features depend on. Codes appear once,
+// in the order they were first added.
+func (cfg ClientConfig) parameterRequestList() ([]byte, error) {
+	profile := cfg.RequestProfile
+	if profile == "" {
+		profile = RequestProfileDesktop
+	}
+	base, exists := requestProfiles[profile]
+	if !exists {
+		return nil, fmt.Errorf("unknown request profile %q", profile)
+	}
+	list := slices.Clone(base)
+
+	for _, name := range cfg.RequestOptions {
+		code, err := ParseRequestOption(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid requested option: %w", err)
+		}
+		if code == OptionPad || code == OptionEnd {
+			return nil, fmt.Errorf("invalid requested option: %s cannot be requested", name)
+		}
+		list = append(list, code)
+	}
+
+	if cfg.IPv6OnlyPreferred {
+		list = append(list, OptionIPv6OnlyPreferred)
+	}
+	if cfg.FetchCaptivePortal {
+		list = append(list, OptionCaptivePortal)
+	}
+	if cfg.Time.requested() {
+		list = append(list, OptionNTPServers, OptionPOSIXTimeZone, OptionTZDatabaseName)
+	}
+	if cfg.Provisioning.StagingDir != "" {
+		list = append(list, OptionTFTPServerName, OptionBootfileName, OptionSZTPRedirect)
+	}
+	if cfg.PXE.Enabled {
+		list = append(list, OptionTFTPServerName, OptionBootfileName)
+	}
+
+	return dedupeCodes(list), nil
+}
+
+// dedupeCodes removes repeated codes, keeping the first occurrence
+func dedupeCodes(codes []byte) []byte {
+	var seen [256]bool
+	result := codes[:0]
+	for _, code := range codes {
+		if !seen[code] {
+			seen[code] = true
+			result = append(result, code)
+		}
+	}
+	return result
+}
+
+// addExtraOptions copies the caller-supplied options. The options the
+// client manages itself cannot be replaced.
+func (cfg ClientConfig) addExtraOptions(options map[byte][]byte) error {
+	for code, value := range cfg.ExtraOptions {
+		switch code {
+		case OptionPad, OptionEnd, OptionDHCPMessageType, OptionParameterRequestList:
+			return fmt.Errorf("extra option %d is managed by the client", code)
+		}
+		if len(value) > 255 {
+			return fmt.Errorf("extra option %d must be at most 255 bytes, got %d", code, len(value))
+		}
+		options[code] = value
+	}
+	return nil
+}
+
+// parameterRequestList returns the option 55 value shared by every
+// message the client sends. Clients not made by NewDHCPClientWithConfig
+// build it from their configuration.
+func (c *DHCPClient) parameterRequestList() []byte {
+	if c.requestList != nil {
+		return c.requestList
+	}
+	list, _ := c.config.parameterRequestList()
+	return list
+}
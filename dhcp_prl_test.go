@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLookupOptionByName(t *testing.T) {
+	tests := []struct {
+		name string
+		code byte
+	}{
+		{"NTP Servers", 42},
+		{"ntp-servers", 42},
+		{"ntp_servers", 42},
+		{"domain search", 119},
+		{"Classless-Static-Route-Option", 121},
+	}
+
+	for _, tt := range tests {
+		info, exists := LookupOptionByName(tt.name)
+		if !exists || info.Code != tt.code {
+			t.Errorf("%q: got %d (found %v), want %d", tt.name, info.Code, exists, tt.code)
+		}
+	}
+
+	if _, exists := LookupOptionByName("unassigned"); exists {
+		t.Error("a name shared by several codes should not resolve")
+	}
+}
+
+func TestParameterRequestListProfiles(t *testing.T) {
+	tests := []struct {
+		profile RequestProfile
+		want    []byte
+	}{
+		{"", []byte{1, 3, 6, 15, 31, 33, 43, 44, 46, 47, 119, 121, 249, 252}},
+		{RequestProfileMinimal, []byte{1, 3, 6, 15}},
+		{RequestProfileServer, []byte{1, 3, 6, 15, 26, 28, 42, 119, 121}},
+		{RequestProfileAnonymous, []byte{1, 3, 6, 15, 119, 121}},
+	}
+
+	for _, tt := range tests {
+		got, err := ClientConfig{RequestProfile: tt.profile}.parameterRequestList()
+		if err != nil {
+			t.Fatalf("profile %q: %v", tt.profile, err)
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("profile %q: got %v, want %v", tt.profile, got, tt.want)
+		}
+	}
+
+	if _, err := (ClientConfig{RequestProfile: "laptop"}).parameterRequestList(); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
+
+func TestParameterRequestListOptions(t *testing.T) {
+	cfg := ClientConfig{
+		RequestProfile: RequestProfileMinimal,
+		RequestOptions: []string{"42", "domain-search", "router"},
+		PXE:            PXEConfig{Enabled: true},
+		Provisioning:   ProvisioningConfig{StagingDir: t.TempDir()},
+	}
+	got, err := cfg.parameterRequestList()
+	if err != nil {
+		t.Fatalf("parameterRequestList: %v", err)
+	}
+	want := []byte{1, 3, 6, 15, 42, 119, OptionTFTPServerName, OptionBootfileName, OptionSZTPRedirect}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for _, name := range []string{"no-such-option", "255", "pad"} {
+		cfg := ClientConfig{RequestOptions: []string{name}}
+		if _, err := cfg.parameterRequestList(); err == nil {
+			t.Errorf("%q: expected an error", name)
+		}
+	}
+}
+
+func TestParameterRequestListShared(t *testing.T) {
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{
+		RequestProfile: RequestProfileServer,
+		RequestOptions: []string{"dhcp-captive-portal"},
+	})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	want := []byte{1, 3, 6, 15, 26, 28, 42, 119, 121, 114}
+	offer := &DHCPMessage{YourIP: 0xc0a80114, NextServerIP: 0xc0a80101, Options: map[byte][]byte{}}
+	messages := map[string]*DHCPMessage{
+		"DISCOVER": client.createDHCPDiscover(),
+		"REQUEST":  client.createDHCPRequest(offer),
+		"RENEW":    client.createDHCPRenew(),
+	}
+	for name, msg := range messages {
+		if got := msg.Options[OptionParameterRequestList]; !bytes.Equal(got, want) {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestExtraOptions(t *testing.T) {
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{
+		ExtraOptions: map[byte][]byte{224: []byte("site-7"), 118: {10, 0, 0, 1}},
+	})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	msg := client.createDHCPDiscover()
+	if got := string(msg.Options[224]); got != "site-7" {
+		t.Errorf("option 224: got %q", got)
+	}
+	if got := msg.Options[118]; !bytes.Equal(got, []byte{10, 0, 0, 1}) {
+		t.Errorf("option 118: got %v", got)
+	}
+
+	for _, code := range []byte{OptionDHCPMessageType, OptionParameterRequestList, OptionEnd} {
+		cfg := ClientConfig{ExtraOptions: map[byte][]byte{code: {1}}}
+		if _, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, cfg); err == nil {
+			t.Errorf("option %d: expected an error", code)
+		}
+	}
+}
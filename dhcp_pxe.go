@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Client system architecture types (option 93, RFC 4578 and the IANA
+// "Processor Architecture Types" registry)
+const (
+	PXEArchX86BIOS   = 0
+	PXEArchEFIIA32   = 6
+	PXEArchEFIBC     = 7
+	PXEArchEFIX8664  = 9
+	PXEArchEFIARM32  = 10
+	PXEArchEFIARM64  = 11
+	PXEArchHTTPX8664 = 16
+)
+
+// PXENDITypeUNDI is the Universal Network Device Interface type carried in
+// option 94
+const PXENDITypeUNDI = 1
+
+// PXEConfig configures PXE network boot client mode
+type PXEConfig struct {
+	// Enabled sends the PXE options 60, 93, 94 and 97 and reports the boot
+	// descriptor from the DHCPACK
+	Enabled bool
+	// Architecture is the client system architecture sent in option 93
+	Architecture uint16
+	// UNDIMajor and UNDIMinor are the UNDI version sent in option 94. Zero
+	// means version 2.1.
+	UNDIMajor uint8
+	UNDIMinor uint8
+	// UUID is the machine identifier sent in option 97
+	UUID [16]byte
+
+	// DownloadDir, if set, is where the boot file is fetched over TFTP
+	DownloadDir string
+	// TFTP configures the download. The zero value uses the defaults.
+	TFTP TFTPClient
+}
+
+// undiVersion returns the configured UNDI version
+func (cfg PXEConfig) undiVersion() (uint8, uint8) {
+	if cfg.UNDIMajor == 0 && cfg.UNDIMinor == 0 {
+		return 2, 1
+	}
+	return cfg.UNDIMajor, cfg.UNDIMinor
+}
+
+// VendorClass returns the "PXEClient:Arch:xxxxx:UNDI:yyyzzz" vendor class
+// identifier PXE firmware sends in option 60
+func (cfg PXEConfig) VendorClass() string {
+	major, minor := cfg.undiVersion()
+	return fmt.Sprintf("PXEClient:Arch:%05d:UNDI:%03d%03d", cfg.Architecture, major, minor)
+}
+
+// addOptions adds the PXE client options to options
+func (cfg PXEConfig) addOptions(options map[byte][]byte) {
+	major, minor := cfg.undiVersion()
+
+	options[OptionVendorClassIdentifier] = []byte(cfg.VendorClass())
+	options[OptionClientSystemArchitecture] = binary.BigEndian.AppendUint16(nil, cfg.Architecture)
+	options[OptionClientNDI] = []byte{PXENDITypeUNDI, major, minor}
+	options[OptionClientMachineID] = append([]byte{0}, cfg.UUID[:]...) // Type 0: UUID
+}
+
+// BootDescriptor describes where a network boot client loads its boot
+// file from
+type BootDescriptor struct {
+	// NextServer is the TFTP server from siaddr
+	NextServer netip.Addr
+	// ServerName is the TFTP server name from sname or option 66
+	ServerName string
+	// BootFile is the boot file name from file or option 67
+	BootFile string
+}
+
+// NewBootDescriptor builds a BootDescriptor from a DHCPOFFER or DHCPACK.
+// The sname and file header fields take precedence; options 66 and 67 are
+// used when the fields are empty or overloaded with options (RFC 2132
+// section 9.4).
+func NewBootDescriptor(msg *DHCPMessage) (*BootDescriptor, error) {
+	desc := &BootDescriptor{}
+	if msg.NextServerIP != 0 {
+		desc.NextServer = uint32ToAddr(msg.NextServerIP)
+	}
+
+	var overload byte
+	if value := msg.Options[OptionOptionOverload]; len(value) == 1 {
+		overload = value[0]
+	}
+	if overload&0x02 == 0 {
+		desc.ServerName = msg.bytesToString(msg.ServerHostName)
+	}
+	if overload&0x01 == 0 {
+		desc.BootFile = msg.bytesToString(msg.BootFileName)
+	}
+	if value, exists := msg.Options[OptionTFTPServerName]; exists && desc.ServerName == "" {
+		desc.ServerName = msg.bytesToString(value)
+	}
+	if value, exists := msg.Options[OptionBootfileName]; exists && desc.BootFile == "" {
+		desc.BootFile = msg.bytesToString(value)
+	}
+
+	if desc.BootFile == "" {
+		return nil, fmt.Errorf("no boot file in reply")
+	}
+	return desc, nil
+}
+
+// TFTPServer returns the host that serves the boot file: the sname or
+// option 66 name if set, otherwise siaddr
+func (d *BootDescriptor) TFTPServer() (string, error) {
+	if d.ServerName != "" {
+		return d.ServerName, nil
+	}
+	if d.NextServer.IsValid() && !d.NextServer.IsUnspecified() {
+		return d.NextServer.String(), nil
+	}
+	return "", fmt.Errorf("no TFTP server in reply")
+}
+
+// String returns a human-readable representation of the descriptor
+func (d *BootDescriptor) String() string {
+	var result strings.Builder
+
+	result.WriteString("Boot Descriptor:\n")
+	if d.NextServer.IsValid() {
+		result.WriteString(fmt.Sprintf("  Next Server: %s\n", d.NextServer))
+	}
+	if d.ServerName != "" {
+		result.WriteString(fmt.Sprintf("  Server Name: %s\n", d.ServerName))
+	}
+	result.WriteString(fmt.Sprintf("  Boot File: %s\n", d.BootFile))
+
+	return result.String()
+}
+
+// FetchBootFile downloads the boot file over TFTP into dir and returns the
+// path it was written to
+func (d *BootDescriptor) FetchBootFile(tftp *TFTPClient, dir string) (string, error) {
+	server, err := d.TFTPServer()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, filepath.Base(filepath.FromSlash(d.BootFile)))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create boot file: %w", err)
+	}
+	defer file.Close()
+
+	port := tftp.ServerPort
+	if port == 0 {
+		port = tftpPort
+	}
+	if _, err := tftp.Fetch(net.JoinHostPort(server, strconv.Itoa(port)), d.BootFile, file); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, file.Close()
+}
+
+// bootPXE reports the boot descriptor of the lease and downloads the boot
+// file when configured
+func (c *DHCPClient) bootPXE(ack *DHCPMessage) error {
+	desc, err := NewBootDescriptor(ack)
+	if err != nil {
+		return fmt.Errorf("PXE boot failed: %w", err)
+	}
+	fmt.Print(desc.String())
+
+	if c.config.PXE.DownloadDir == "" {
+		return nil
+	}
+	path, err := desc.FetchBootFile(&c.config.PXE.TFTP, c.config.PXE.DownloadDir)
+	if err != nil {
+		return fmt.Errorf("failed to download boot file: %w", err)
+	}
+	fmt.Printf("Downloaded boot file to %s\n", path)
+	return nil
+}
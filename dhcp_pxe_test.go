@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPXEClientOptions(t *testing.T) {
+	uuid := [16]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{
+		PXE: PXEConfig{Enabled: true, Architecture: PXEArchEFIX8664, UUID: uuid},
+	})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	msg := client.createDHCPDiscover()
+	if got := string(msg.Options[OptionVendorClassIdentifier]); got != "PXEClient:Arch:00009:UNDI:002001" {
+		t.Errorf("vendor class = %q", got)
+	}
+	if got := msg.Options[OptionClientSystemArchitecture]; !bytes.Equal(got, []byte{0, 9}) {
+		t.Errorf("option 93 = %v", got)
+	}
+	if got := msg.Options[OptionClientNDI]; !bytes.Equal(got, []byte{PXENDITypeUNDI, 2, 1}) {
+		t.Errorf("option 94 = %v", got)
+	}
+	if got := msg.Options[OptionClientMachineID]; len(got) != 17 || got[0] != 0 || !bytes.Equal(got[1:], uuid[:]) {
+		t.Errorf("option 97 = %v", got)
+	}
+	if prl := msg.Options[OptionParameterRequestList]; !bytes.Contains(prl, []byte{OptionTFTPServerName, OptionBootfileName}) {
+		t.Errorf("parameter request list %v is missing options 66 and 67", prl)
+	}
+}
+
+func TestNewBootDescriptor(t *testing.T) {
+	msg := &DHCPMessage{
+		NextServerIP:   0x0a000002,
+		ServerHostName: make([]byte, SizeServerHostName),
+		BootFileName:   make([]byte, SizeBootFileName),
+		Options:        map[byte][]byte{},
+	}
+	copy(msg.BootFileName, "pxelinux.0")
+
+	desc, err := NewBootDescriptor(msg)
+	if err != nil {
+		t.Fatalf("NewBootDescriptor: %v", err)
+	}
+	if desc.NextServer != netip.MustParseAddr("10.0.0.2") || desc.BootFile != "pxelinux.0" {
+		t.Fatalf("unexpected descriptor %+v", desc)
+	}
+	if server, _ := desc.TFTPServer(); server != "10.0.0.2" {
+		t.Fatalf("TFTP server = %q", server)
+	}
+
+	// Options 66 and 67 are used when the header fields carry options,
+	// which must be parsed from the serialized file and sname fields
+	msg.ClientHardwareAddress = make([]byte, SizeClientHardwareAddress)
+	msg.MagicCookie = DHCPMagicCookie
+	msg.Options[OptionOptionOverload] = []byte{3}
+	file := append([]byte{OptionBootfileName, 15}, "efi/grubx64.efi"...)
+	copy(msg.BootFileName, append(file, OptionEnd))
+	sname := append([]byte{OptionTFTPServerName, 16}, "tftp.example.net"...)
+	copy(msg.ServerHostName, append(sname, OptionEnd))
+	data, err := msg.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	desc, err = NewBootDescriptor(mustDeserialize(t, data))
+	if err != nil {
+		t.Fatalf("NewBootDescriptor: %v", err)
+	}
+	if desc.ServerName != "tftp.example.net" || desc.BootFile != "efi/grubx64.efi" {
+		t.Fatalf("unexpected descriptor %+v", desc)
+	}
+
+	if _, err := NewBootDescriptor(&DHCPMessage{
+		ServerHostName: make([]byte, SizeServerHostName),
+		BootFileName:   make([]byte, SizeBootFileName),
+	}); err == nil {
+		t.Fatal("expected error without a boot file")
+	}
+}
+
+func TestFetchBootFile(t *testing.T) {
+	want := testFile(2000)
+	server := startTestTFTPServer(t, map[string][]byte{"efi/grubx64.efi": want}, false)
+	host, port, _ := net.SplitHostPort(server.addr())
+
+	desc := &BootDescriptor{ServerName: host, BootFile: "efi/grubx64.efi"}
+	tftp := &TFTPClient{Timeout: time.Second}
+	tftp.ServerPort, _ = strconv.Atoi(port)
+
+	path, err := desc.FetchBootFile(tftp, t.TempDir())
+	if err != nil {
+		t.Fatalf("FetchBootFile: %v", err)
+	}
+	if filepath.Base(path) != "grubx64.efi" {
+		t.Fatalf("boot file written to %s", path)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("downloaded %d bytes, want %d (%v)", len(got), len(want), err)
+	}
+}
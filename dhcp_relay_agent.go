@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Relay Agent Information sub-option codes
+const (
+	RelayAgentCircuitID        = 1  // RFC 3046
+	RelayAgentRemoteID         = 2  // RFC 3046
+	RelayAgentLinkSelection    = 5  // RFC 3527
+	RelayAgentSubscriberID     = 6  // RFC 3993
+	RelayAgentServerIDOverride = 11 // RFC 5107
+)
+
+// RelayAgentInfo is the decoded content of option 82 (RFC 3046)
+type RelayAgentInfo struct {
+	CircuitID        []byte
+	RemoteID         []byte
+	LinkSelection    netip.Addr
+	SubscriberID     string
+	ServerIDOverride netip.Addr
+	Other            []SubOption // Sub-options without a dedicated field
+}
+
+// DecodeRelayAgentInfo decodes the sub-options of option 82
+func DecodeRelayAgentInfo(data []byte) (*RelayAgentInfo, error) {
+	subOptions, err := DecodeSubOptions(data, false)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &RelayAgentInfo{}
+	for _, subOption := range subOptions {
+		switch subOption.Code {
+		case RelayAgentCircuitID:
+			info.CircuitID = subOption.Data
+		case RelayAgentRemoteID:
+			info.RemoteID = subOption.Data
+		case RelayAgentLinkSelection:
+			if len(subOption.Data) != 4 {
+				return nil, fmt.Errorf("link-selection sub-option must be 4 bytes, got %d", len(subOption.Data))
+			}
+			info.LinkSelection = netip.AddrFrom4([4]byte(subOption.Data))
+		case RelayAgentSubscriberID:
+			info.SubscriberID = string(subOption.Data)
+		case RelayAgentServerIDOverride:
+			if len(subOption.Data) != 4 {
+				return nil, fmt.Errorf("server-id-override sub-option must be 4 bytes, got %d", len(subOption.Data))
+			}
+			info.ServerIDOverride = netip.AddrFrom4([4]byte(subOption.Data))
+		default:
+			info.Other = append(info.Other, subOption)
+		}
+	}
+
+	return info, nil
+}
+
+// Encode encodes the relay agent information as the value of option 82
+func (r *RelayAgentInfo) Encode() ([]byte, error) {
+	var subOptions []SubOption
+	if r.CircuitID != nil {
+		subOptions = append(subOptions, SubOption{Code: RelayAgentCircuitID, Data: r.CircuitID})
+	}
+	if r.RemoteID != nil {
+		subOptions = append(subOptions, SubOption{Code: RelayAgentRemoteID, Data: r.RemoteID})
+	}
+	if r.LinkSelection.IsValid() {
+		if !r.LinkSelection.Is4() {
+			return nil, fmt.Errorf("link-selection %s is not an IPv4 address", r.LinkSelection)
+		}
+		addr := r.LinkSelection.As4()
+		subOptions = append(subOptions, SubOption{Code: RelayAgentLinkSelection, Data: addr[:]})
+	}
+	if r.SubscriberID != "" {
+		subOptions = append(subOptions, SubOption{Code: RelayAgentSubscriberID, Data: []byte(r.SubscriberID)})
+	}
+	if r.ServerIDOverride.IsValid() {
+		if !r.ServerIDOverride.Is4() {
+			return nil, fmt.Errorf("server-id-override %s is not an IPv4 address", r.ServerIDOverride)
+		}
+		addr := r.ServerIDOverride.As4()
+		subOptions = append(subOptions, SubOption{Code: RelayAgentServerIDOverride, Data: addr[:]})
+	}
+	subOptions = append(subOptions, r.Other...)
+
+	return EncodeSubOptions(subOptions)
+}
+
+// String returns a human-readable representation of the sub-options
+func (r *RelayAgentInfo) String() string {
+	var parts []string
+	if r.CircuitID != nil {
+		parts = append(parts, fmt.Sprintf("Circuit-ID=%s", opaqueString(r.CircuitID)))
+	}
+	if r.RemoteID != nil {
+		parts = append(parts, fmt.Sprintf("Remote-ID=%s", opaqueString(r.RemoteID)))
+	}
+	if r.LinkSelection.IsValid() {
+		parts = append(parts, fmt.Sprintf("Link-Selection=%s", r.LinkSelection))
+	}
+	if r.SubscriberID != "" {
+		parts = append(parts, fmt.Sprintf("Subscriber-ID='%s'", r.SubscriberID))
+	}
+	if r.ServerIDOverride.IsValid() {
+		parts = append(parts, fmt.Sprintf("Server-ID-Override=%s", r.ServerIDOverride))
+	}
+	for _, subOption := range r.Other {
+		parts = append(parts, fmt.Sprintf("Sub-option %d=%s", subOption.Code, opaqueString(subOption.Data)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// opaqueString formats an opaque identifier as text when printable and as
+// colon-separated hex otherwise
+func opaqueString(data []byte) string {
+	if len(data) > 0 && isPrintable(data) {
+		return fmt.Sprintf("'%s'", string(data))
+	}
+
+	hex := make([]string, len(data))
+	for i, b := range data {
+		hex[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(hex, ":")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestRelayAgentInfoRoundTrip(t *testing.T) {
+	info := &RelayAgentInfo{
+		CircuitID:        []byte("eth0/1/3:100"),
+		RemoteID:         []byte{0x00, 0x1b, 0x21, 0x3c, 0x4d, 0x5e},
+		LinkSelection:    netip.MustParseAddr("10.20.0.0"),
+		SubscriberID:     "customer-4711",
+		ServerIDOverride: netip.MustParseAddr("10.20.0.1"),
+		Other:            []SubOption{{Code: 9, Data: []byte{0, 0, 0, 9, 1, 0xff}}},
+	}
+
+	data, err := info.Encode()
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	decoded, err := DecodeRelayAgentInfo(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if !bytes.Equal(decoded.CircuitID, info.CircuitID) || !bytes.Equal(decoded.RemoteID, info.RemoteID) {
+		t.Errorf("identifiers mismatch: got %s", decoded)
+	}
+	if decoded.LinkSelection != info.LinkSelection || decoded.ServerIDOverride != info.ServerIDOverride {
+		t.Errorf("addresses mismatch: got %s", decoded)
+	}
+	if decoded.SubscriberID != info.SubscriberID {
+		t.Errorf("subscriber-id: got %q, want %q", decoded.SubscriberID, info.SubscriberID)
+	}
+	if len(decoded.Other) != 1 || decoded.Other[0].Code != 9 {
+		t.Errorf("unexpected other sub-options: %v", decoded.Other)
+	}
+}
+
+func TestRelayAgentInfoString(t *testing.T) {
+	m := &DHCPMessage{}
+	value := []byte{
+		RelayAgentCircuitID, 4, 'p', 'o', 'r', 't',
+		RelayAgentRemoteID, 2, 0xde, 0xad,
+		RelayAgentLinkSelection, 4, 192, 168, 5, 0,
+	}
+
+	got := m.optionValueString(OptionRelayAgentInformation, value)
+	for _, want := range []string{"Circuit-ID='port'", "Remote-ID=de:ad", "Link-Selection=192.168.5.0"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestDecodeRelayAgentInfoRejectsTruncated(t *testing.T) {
+	if _, err := DecodeRelayAgentInfo([]byte{RelayAgentCircuitID, 5, 'a'}); err == nil {
+		t.Fatal("expected error for truncated sub-option")
+	}
+	if _, err := DecodeRelayAgentInfo([]byte{RelayAgentLinkSelection, 2, 10, 0}); err == nil {
+		t.Fatal("expected error for short link-selection")
+	}
+}
+
+func TestDecodeRelayAgentInfoKeepsCodes0And255(t *testing.T) {
+	// Option 82 has no pad or end, so codes 0 and 255 are sub-options
+	info, err := DecodeRelayAgentInfo([]byte{0, 1, 'a', 255, 2, 'b', 'c', RelayAgentCircuitID, 1, 'x'})
+	if err != nil {
+		t.Fatalf("DecodeRelayAgentInfo: %v", err)
+	}
+	if len(info.Other) != 2 || info.Other[0].Code != 0 || info.Other[1].Code != 255 || !bytes.Equal(info.Other[1].Data, []byte("bc")) {
+		t.Fatalf("unexpected sub-options %+v", info.Other)
+	}
+	if !bytes.Equal(info.CircuitID, []byte("x")) {
+		t.Fatalf("circuit ID after code 255 lost: %q", info.CircuitID)
+	}
+}
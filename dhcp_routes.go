@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// Route is a classless static route from option 121 or 249 (RFC 3442)
+type Route struct {
+	Destination netip.Prefix
+	Gateway     netip.Addr
+}
+
+// String returns the route in "destination via gateway" form
+func (r Route) String() string {
+	return fmt.Sprintf("%s via %s", r.Destination, r.Gateway)
+}
+
+// DecodeClasslessRoutes decodes the compact destination encoding used by
+// options 121 and 249. Each route is a prefix width, the significant octets
+// of the destination and a four byte gateway address.
+func DecodeClasslessRoutes(data []byte) ([]Route, error) {
+	var routes []Route
+	for i := 0; i < len(data); {
+		width := int(data[i])
+		if width > 32 {
+			return nil, fmt.Errorf("invalid route prefix width %d at offset %d", width, i)
+		}
+		i++
+
+		significant := (width + 7) / 8
+		if i+significant+4 > len(data) {
+			return nil, fmt.Errorf("truncated route at offset %d: need %d bytes, have %d", i-1, significant+4, len(data)-i)
+		}
+
+		var dest [4]byte
+		copy(dest[:], data[i:i+significant])
+		i += significant
+
+		gateway := netip.AddrFrom4([4]byte(data[i : i+4]))
+		i += 4
+
+		routes = append(routes, Route{
+			Destination: netip.PrefixFrom(netip.AddrFrom4(dest), width).Masked(),
+			Gateway:     gateway,
+		})
+	}
+
+	return routes, nil
+}
+
+// EncodeClasslessRoutes encodes routes in the option 121/249 wire format
+func EncodeClasslessRoutes(routes []Route) ([]byte, error) {
+	var data []byte
+	for _, route := range routes {
+		if !route.Destination.IsValid() || !route.Destination.Addr().Is4() {
+			return nil, fmt.Errorf("route destination %s is not an IPv4 prefix", route.Destination)
+		}
+		if !route.Gateway.Is4() {
+			return nil, fmt.Errorf("route gateway %s is not an IPv4 address", route.Gateway)
+		}
+
+		width := route.Destination.Bits()
+		dest := route.Destination.Masked().Addr().As4()
+		gateway := route.Gateway.As4()
+
+		data = append(data, byte(width))
+		data = append(data, dest[:(width+7)/8]...)
+		data = append(data, gateway[:]...)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"net/netip"
+	"testing"
+)
+
+func TestClasslessRoutesRoundTrip(t *testing.T) {
+	routes := []Route{
+		{Destination: netip.MustParsePrefix("0.0.0.0/0"), Gateway: netip.MustParseAddr("192.168.1.1")},
+		{Destination: netip.MustParsePrefix("10.0.0.0/8"), Gateway: netip.MustParseAddr("192.168.1.2")},
+		{Destination: netip.MustParsePrefix("172.16.32.0/20"), Gateway: netip.MustParseAddr("192.168.1.3")},
+		{Destination: netip.MustParsePrefix("192.168.100.7/32"), Gateway: netip.MustParseAddr("0.0.0.0")},
+	}
+
+	data, err := EncodeClasslessRoutes(routes)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	want := []byte{
+		0, 192, 168, 1, 1,
+		8, 10, 192, 168, 1, 2,
+		20, 172, 16, 32, 192, 168, 1, 3,
+		32, 192, 168, 100, 7, 0, 0, 0, 0,
+	}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("encode: got %v, want %v", data, want)
+	}
+
+	decoded, err := DecodeClasslessRoutes(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(decoded) != len(routes) {
+		t.Fatalf("decode: got %d routes, want %d", len(decoded), len(routes))
+	}
+	for i := range routes {
+		if decoded[i] != routes[i] {
+			t.Errorf("route %d: got %s, want %s", i, decoded[i], routes[i])
+		}
+	}
+}
+
+func TestDecodeClasslessRoutesRejectsMalformed(t *testing.T) {
+	tests := map[string][]byte{
+		"width too large": {33, 10, 0, 0, 0, 0, 1, 1, 1, 1},
+		"truncated":       {24, 10, 0, 0, 192, 168},
+	}
+
+	for name, data := range tests {
+		if _, err := DecodeClasslessRoutes(data); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLeaseIgnoresRouterWhenClasslessRoutesPresent(t *testing.T) {
+	msg := &DHCPMessage{
+		YourIP: 0xc0a80164,
+		Options: map[byte][]byte{
+			OptionDHCPMessageType:      {DHCPAck},
+			OptionRouter:               {192, 168, 1, 1},
+			OptionClasslessStaticRoute: {8, 10, 192, 168, 1, 254},
+		},
+	}
+
+	lease, err := NewLease(msg)
+	if err != nil {
+		t.Fatalf("NewLease: %v", err)
+	}
+	if len(lease.Routers) != 0 {
+		t.Errorf("expected routers to be ignored, got %v", lease.Routers)
+	}
+	if len(lease.Routes) != 1 || lease.Routes[0].String() != "10.0.0.0/8 via 192.168.1.254" {
+		t.Errorf("unexpected routes: %v", lease.Routes)
+	}
+
+	delete(msg.Options, OptionClasslessStaticRoute)
+	lease, err = NewLease(msg)
+	if err != nil {
+		t.Fatalf("NewLease: %v", err)
+	}
+	if len(lease.Routers) != 1 || lease.Routers[0] != netip.MustParseAddr("192.168.1.1") {
+		t.Errorf("expected router 192.168.1.1, got %v", lease.Routers)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+)
+
+// ServerPolicy decides which DHCP servers the client accepts replies from.
+// A reply is rejected when its server identifier (option 54) or source
+// address is denied, or when an allow list is set and does not contain it.
+// The zero value accepts every server.
+type ServerPolicy struct {
+	// AllowedServerIDs and AllowedSources, when set, list the only server
+	// identifiers and source addresses accepted. Replies relayed by a
+	// DHCP relay come from the relay's address.
+	AllowedServerIDs []netip.Prefix
+	AllowedSources   []netip.Prefix
+	// DeniedServerIDs and DeniedSources are always rejected
+	DeniedServerIDs []netip.Prefix
+	DeniedSources   []netip.Prefix
+}
+
+// Check returns an error if the policy rejects a reply from the server
+// with the given identifier, received from source
+func (p ServerPolicy) Check(serverID, source netip.Addr) error {
+	if prefixesContain(p.DeniedServerIDs, serverID) {
+		return fmt.Errorf("server identifier %s is denied", serverID)
+	}
+	if prefixesContain(p.DeniedSources, source) {
+		return fmt.Errorf("source address %s is denied", source)
+	}
+	if len(p.AllowedServerIDs) > 0 && !prefixesContain(p.AllowedServerIDs, serverID) {
+		return fmt.Errorf("server identifier %s is not allowed", displayAddr(serverID))
+	}
+	if len(p.AllowedSources) > 0 && !prefixesContain(p.AllowedSources, source) {
+		return fmt.Errorf("source address %s is not allowed", displayAddr(source))
+	}
+	return nil
+}
+
+// restricted reports whether the policy names the legitimate servers
+func (p ServerPolicy) restricted() bool {
+	return len(p.AllowedServerIDs) > 0 || len(p.AllowedSources) > 0
+}
+
+// prefixesContain reports whether any prefix contains addr
+func prefixesContain(prefixes []netip.Prefix, addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// displayAddr formats an address that may be missing
+func displayAddr(addr netip.Addr) string {
+	if !addr.IsValid() {
+		return "(none)"
+	}
+	return addr.String()
+}
+
+// checkServer applies the server policy to a reply
+func (c *DHCPClient) checkServer(msg *DHCPMessage, from *net.UDPAddr) error {
+	return c.config.Servers.Check(replyServerID(msg), udpAddrIP(from))
+}
+
+// udpAddrIP returns the IPv4 address of a UDP peer
+func udpAddrIP(addr *net.UDPAddr) netip.Addr {
+	if addr == nil {
+		return netip.Addr{}
+	}
+	ip, _ := netip.AddrFromSlice(addr.IP)
+	return ip.Unmap()
+}
+
+// Exit codes of server detection, following the monitoring plugin
+// convention
+const (
+	DetectOK       = 0 // Only accepted servers answered
+	DetectWarning  = 1 // No server answered, or several did and no allow list says which is legitimate
+	DetectCritical = 2 // A server the policy rejects answered
+	DetectUnknown  = 3 // Detection itself failed
+)
+
+// DetectedServer is a server that answered the detection DHCPDISCOVER
+type DetectedServer struct {
+	ServerID netip.Addr
+	Source   netip.Addr
+	// Offer holds the offered parameters. It is nil if the offer could not
+	// be parsed, in which case OfferError says why.
+	Offer      *Lease
+	OfferError error
+	// Rejected says why the server policy rejects the server, or is nil
+	Rejected error
+}
+
+// DetectionReport lists every server that answered during detection
+type DetectionReport struct {
+	Servers []DetectedServer
+	// restricted is set when the policy has an allow list
+	restricted bool
+}
+
+// add records an offer, ignoring repeated offers from the same server
+func (r *DetectionReport) add(server DetectedServer) {
+	for _, known := range r.Servers {
+		if known.ServerID == server.ServerID && known.Source == server.Source {
+			return
+		}
+	}
+	r.Servers = append(r.Servers, server)
+}
+
+// ExitCode summarizes the report for monitoring systems
+func (r *DetectionReport) ExitCode() int {
+	for _, server := range r.Servers {
+		if server.Rejected != nil {
+			return DetectCritical
+		}
+	}
+	if len(r.Servers) == 0 || (len(r.Servers) > 1 && !r.restricted) {
+		return DetectWarning
+	}
+	return DetectOK
+}
+
+// String returns a human-readable representation of the report
+func (r *DetectionReport) String() string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Detected %d DHCP server(s):\n", len(r.Servers)))
+	for _, server := range r.Servers {
+		status := "accepted"
+		if server.Rejected != nil {
+			status = "ROGUE: " + server.Rejected.Error()
+		}
+		result.WriteString(fmt.Sprintf("Server %s from %s (%s)\n", displayAddr(server.ServerID), displayAddr(server.Source), status))
+		if server.Offer != nil {
+			result.WriteString(server.Offer.String())
+		} else {
+			result.WriteString(fmt.Sprintf("  Unparseable offer: %v\n", server.OfferError))
+		}
+	}
+
+	return result.String()
+}
+
+// DetectServers broadcasts a DHCPDISCOVER and reports every server that
+// offers an address within window. No address is requested.
+func (c *DHCPClient) DetectServers(window time.Duration) (*DetectionReport, error) {
+	if err := c.createSockets(); err != nil {
+		return nil, fmt.Errorf("failed to create sockets: %w", err)
+	}
+	defer c.cleanup()
+
+	return c.detectServers(window)
+}
+
+// detectServers collects offers on the open sockets
+func (c *DHCPClient) detectServers(window time.Duration) (*DetectionReport, error) {
+	discoverMsg := c.createDHCPDiscover()
+	// A Rapid Commit server would commit a lease instead of offering one
+	delete(discoverMsg.Options, OptionRapidCommit)
+	fmt.Println("Sending DHCPDISCOVER to detect servers...")
+	if err := c.sendMessage(discoverMsg); err != nil {
+		return nil, fmt.Errorf("failed to send DHCPDISCOVER: %w", err)
+	}
+
+	report := &DetectionReport{restricted: c.config.Servers.restricted()}
+	err := c.receiveReplies(window, func(msg *DHCPMessage, from *net.UDPAddr) bool {
+		msgType := msg.Options[OptionDHCPMessageType]
+		if len(msgType) == 0 || msgType[0] != DHCPOffer || msg.TransactionID != discoverMsg.TransactionID {
+			return false
+		}
+
+		server := DetectedServer{Source: udpAddrIP(from), Rejected: c.checkServer(msg, from)}
+		server.ServerID, _ = optionAddr(msg, OptionServerIdentifier)
+		server.Offer, server.OfferError = c.newLease(msg)
+		report.add(server)
+		return false
+	})
+	if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestServerPolicyCheck(t *testing.T) {
+	policy := ServerPolicy{
+		AllowedServerIDs: []netip.Prefix{netip.MustParsePrefix("10.0.0.1/32")},
+		AllowedSources:   []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")},
+		DeniedSources:    []netip.Prefix{netip.MustParsePrefix("10.0.0.66/32")},
+	}
+
+	tests := []struct {
+		serverID, source string
+		accepted         bool
+	}{
+		{"10.0.0.1", "10.0.0.1", true},
+		{"10.0.0.1", "10.0.0.254", true}, // Relayed
+		{"10.0.0.66", "10.0.0.66", false},
+		{"10.0.0.1", "10.0.0.66", false}, // Spoofed server identifier
+		{"10.0.0.1", "192.168.1.1", false},
+		{"", "10.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		var serverID netip.Addr
+		if tt.serverID != "" {
+			serverID = netip.MustParseAddr(tt.serverID)
+		}
+		err := policy.Check(serverID, netip.MustParseAddr(tt.source))
+		if (err == nil) != tt.accepted {
+			t.Errorf("server %q from %s: got %v, want accepted %v", tt.serverID, tt.source, err, tt.accepted)
+		}
+	}
+
+	if err := (ServerPolicy{}).Check(netip.Addr{}, netip.MustParseAddr("192.168.1.1")); err != nil {
+		t.Errorf("zero policy rejected a server: %v", err)
+	}
+}
+
+// offerFrom builds a serialized DHCPOFFER from the server with identifier
+// serverID
+func offerFrom(t *testing.T, xid uint32, serverID [4]byte) []byte {
+	t.Helper()
+
+	msg := &DHCPMessage{
+		OpCode:                BootReply,
+		HardwareType:          HardwareTypeEthernet,
+		HardwareAddressLength: 6,
+		TransactionID:         xid,
+		YourIP:                0x0a000005,
+		ClientHardwareAddress: make([]byte, SizeClientHardwareAddress),
+		ServerHostName:        make([]byte, SizeServerHostName),
+		BootFileName:          make([]byte, SizeBootFileName),
+		MagicCookie:           DHCPMagicCookie,
+		Options: map[byte][]byte{
+			OptionDHCPMessageType:    {DHCPOffer},
+			OptionServerIdentifier:   serverID[:],
+			OptionIPAddressLeaseTime: {0, 0, 0x0e, 0x10},
+		},
+	}
+	data, err := msg.Serialize()
+	if err != nil {
+		t.Fatalf("serialize offer: %v", err)
+	}
+	return data
+}
+
+// startServers answers the first packet received on listener with an
+// offer from each of the given server identifiers
+func startServers(t *testing.T, listener *net.UDPConn, client *net.UDPAddr, serverIDs ...[4]byte) {
+	t.Helper()
+
+	offers := make([][]byte, len(serverIDs))
+	for i, serverID := range serverIDs {
+		offers[i] = offerFrom(t, 0x12345678, serverID)
+	}
+
+	go func() {
+		buf := make([]byte, 1500)
+		_ = listener.SetReadDeadline(time.Now().Add(3 * time.Second))
+		if _, _, err := listener.ReadFromUDP(buf); err != nil {
+			return
+		}
+		for _, offer := range offers {
+			_, _ = listener.WriteToUDP(offer, client)
+		}
+	}()
+}
+
+// detectionClient returns a client whose DISCOVER goes to a loopback
+// listener
+func detectionClient(t *testing.T, config ClientConfig) (*DHCPClient, *net.UDPConn) {
+	t.Helper()
+
+	listener, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen server: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	receive, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen client: %v", err)
+	}
+	t.Cleanup(func() { receive.Close() })
+
+	send, err := net.DialUDP("udp4", nil, listener.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("dial server: %v", err)
+	}
+	t.Cleanup(func() { send.Close() })
+
+	client := &DHCPClient{
+		macAddr:       []byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55},
+		transactionID: 0x12345678,
+		sendSocket:    send,
+		receiveSocket: receive,
+		config:        config,
+	}
+	return client, listener
+}
+
+func TestRejectedServerOfferIgnored(t *testing.T) {
+	client, listener := detectionClient(t, ClientConfig{
+		Servers: ServerPolicy{AllowedServerIDs: []netip.Prefix{netip.MustParsePrefix("10.0.0.1/32")}},
+	})
+	startServers(t, listener, client.receiveSocket.LocalAddr().(*net.UDPAddr), [4]byte{10, 0, 0, 66}, [4]byte{10, 0, 0, 1})
+
+	if err := client.sendMessage(client.createDHCPDiscover()); err != nil {
+		t.Fatalf("send discover: %v", err)
+	}
+	offer, err := client.waitForOffer(3 * time.Second)
+	if err != nil {
+		t.Fatalf("waitForOffer: %v", err)
+	}
+	if serverID, _ := optionAddr(offer, OptionServerIdentifier); serverID != netip.MustParseAddr("10.0.0.1") {
+		t.Fatalf("accepted an offer from %s", serverID)
+	}
+}
+
+func TestDetectServers(t *testing.T) {
+	policy := ServerPolicy{AllowedServerIDs: []netip.Prefix{netip.MustParsePrefix("10.0.0.1/32")}}
+	client, listener := detectionClient(t, ClientConfig{Servers: policy})
+	startServers(t, listener, client.receiveSocket.LocalAddr().(*net.UDPAddr),
+		[4]byte{10, 0, 0, 1}, [4]byte{10, 0, 0, 66}, [4]byte{10, 0, 0, 1})
+
+	report, err := client.detectServers(500 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("detectServers: %v", err)
+	}
+	if len(report.Servers) != 2 {
+		t.Fatalf("expected 2 servers, got:\n%s", report)
+	}
+	legit, rogue := report.Servers[0], report.Servers[1]
+	if legit.ServerID != netip.MustParseAddr("10.0.0.1") || legit.Rejected != nil {
+		t.Errorf("unexpected first server %+v", legit)
+	}
+	if rogue.ServerID != netip.MustParseAddr("10.0.0.66") || rogue.Rejected == nil {
+		t.Errorf("unexpected second server %+v", rogue)
+	}
+	if rogue.Offer == nil || rogue.Offer.IP != netip.MustParseAddr("10.0.0.5") {
+		t.Errorf("offered parameters not recorded: %+v", rogue.Offer)
+	}
+	if code := report.ExitCode(); code != DetectCritical {
+		t.Errorf("exit code %d, want %d", code, DetectCritical)
+	}
+}
+
+func TestDetectionExitCodes(t *testing.T) {
+	one := DetectedServer{ServerID: netip.MustParseAddr("10.0.0.1")}
+	two := DetectedServer{ServerID: netip.MustParseAddr("10.0.0.2")}
+
+	tests := []struct {
+		name   string
+		report DetectionReport
+		want   int
+	}{
+		{"no servers", DetectionReport{}, DetectWarning},
+		{"one server", DetectionReport{Servers: []DetectedServer{one}}, DetectOK},
+		{"two servers, no allow list", DetectionReport{Servers: []DetectedServer{one, two}}, DetectWarning},
+		{"two allowed servers", DetectionReport{Servers: []DetectedServer{one, two}, restricted: true}, DetectOK},
+	}
+
+	for _, tt := range tests {
+		if got := tt.report.ExitCode(); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
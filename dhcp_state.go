@@ -0,0 +1,55 @@
+package main
+
+import "fmt"
+
+// ClientState is a state of the RFC 2131 client state machine
+type ClientState uint8
+
+// Client states (RFC 2131 section 4.4)
+const (
+	StateInit ClientState = iota
+	StateSelecting
+	StateRequesting
+	StateBound
+	StateRenewing
+	StateRebinding
+	// StateV6OnlyWait is the RFC 8925 state in which DHCPv4 is paused
+	// because the network prefers IPv6-only clients
+	StateV6OnlyWait
+)
+
+// String returns the RFC 2131 name of the state
+func (s ClientState) String() string {
+	switch s {
+	case StateInit:
+		return "INIT"
+	case StateSelecting:
+		return "SELECTING"
+	case StateRequesting:
+		return "REQUESTING"
+	case StateBound:
+		return "BOUND"
+	case StateRenewing:
+		return "RENEWING"
+	case StateRebinding:
+		return "REBINDING"
+	case StateV6OnlyWait:
+		return "V6ONLY_WAIT"
+	default:
+		return fmt.Sprintf("ClientState(%d)", uint8(s))
+	}
+}
+
+// State returns the current state of the client
+func (c *DHCPClient) State() ClientState {
+	return c.state
+}
+
+// setState moves the client to a new state and reports the change
+func (c *DHCPClient) setState(state ClientState) {
+	from := c.state
+	c.state = state
+	if from != state && c.config.OnStateChange != nil {
+		c.config.OnStateChange(from, state)
+	}
+}
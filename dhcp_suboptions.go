@@ -0,0 +1,54 @@
+package main
+
+import "fmt"
+
+// SubOption is a code/length/value entry encapsulated inside an option,
+// such as the sub-options of options 43 and 82
+type SubOption struct {
+	Code byte
+	Data []byte
+}
+
+// DecodeSubOptions splits data into code/length/value sub-options. With
+// padEnd, pad (0) and end (255) codes are honoured as in the top-level
+// option space, as option 43 requires (RFC 2132 section 8.4). Options 82
+// and 125 have no pad or end, so 0 and 255 are ordinary codes there.
+func DecodeSubOptions(data []byte, padEnd bool) ([]SubOption, error) {
+	var subOptions []SubOption
+	for i := 0; i < len(data); {
+		code := data[i]
+		if padEnd && code == OptionPad {
+			i++
+			continue
+		}
+		if padEnd && code == OptionEnd {
+			break
+		}
+
+		if i+1 >= len(data) {
+			return nil, fmt.Errorf("truncated sub-option %d at offset %d", code, i)
+		}
+		length := int(data[i+1])
+		if i+2+length > len(data) {
+			return nil, fmt.Errorf("sub-option %d at offset %d needs %d bytes, have %d", code, i, length, len(data)-i-2)
+		}
+
+		subOptions = append(subOptions, SubOption{Code: code, Data: data[i+2 : i+2+length]})
+		i += 2 + length
+	}
+
+	return subOptions, nil
+}
+
+// EncodeSubOptions encodes sub-options in code/length/value form
+func EncodeSubOptions(subOptions []SubOption) ([]byte, error) {
+	var data []byte
+	for _, subOption := range subOptions {
+		if len(subOption.Data) > 255 {
+			return nil, fmt.Errorf("sub-option %d too long: %d bytes", subOption.Code, len(subOption.Data))
+		}
+		data = append(data, subOption.Code, byte(len(subOption.Data)))
+		data = append(data, subOption.Data...)
+	}
+	return data, nil
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tftpPort is the well-known TFTP server port
+const tftpPort = 69
+
+// TFTP opcodes (RFC 1350, RFC 2347)
+const (
+	tftpOpRRQ   = 1
+	tftpOpWRQ   = 2
+	tftpOpDATA  = 3
+	tftpOpACK   = 4
+	tftpOpERROR = 5
+	tftpOpOACK  = 6
+)
+
+// TFTP block sizes (RFC 1350, RFC 2348)
+const (
+	tftpDefaultBlockSize = 512
+	tftpMinBlockSize     = 8
+	tftpMaxBlockSize     = 65464
+)
+
+// TFTPClient downloads files with TFTP (RFC 1350), negotiating the block
+// size (RFC 2348) and transfer size (RFC 2349) options
+type TFTPClient struct {
+	// BlockSize is the block size to request. Zero requests 1468 bytes,
+	// which fits an Ethernet frame; 512 disables the option.
+	BlockSize int
+	// Timeout is how long to wait for each packet. Zero means 5 seconds.
+	Timeout time.Duration
+	// Retries is how often a packet is retransmitted. Zero means 5.
+	Retries int
+	// ServerPort is the port BootDescriptor.FetchBootFile contacts. Zero
+	// means 69.
+	ServerPort int
+}
+
+// TFTPError is an ERROR packet sent by the server
+type TFTPError struct {
+	Code    uint16
+	Message string
+}
+
+func (e *TFTPError) Error() string {
+	return fmt.Sprintf("TFTP error %d: %s", e.Code, e.Message)
+}
+
+// Fetch downloads filename from server (host:port) and writes it to w,
+// returning the number of bytes written
+func (c *TFTPClient) Fetch(server, filename string, w io.Writer) (int64, error) {
+	serverAddr, err := net.ResolveUDPAddr("udp", server)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve TFTP server: %w", err)
+	}
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create TFTP socket: %w", err)
+	}
+	defer conn.Close()
+
+	blockSize := c.BlockSize
+	if blockSize == 0 {
+		blockSize = 1468
+	}
+	if blockSize < tftpMinBlockSize || blockSize > tftpMaxBlockSize {
+		return 0, fmt.Errorf("invalid TFTP block size %d", blockSize)
+	}
+
+	options := map[string]string{"tsize": "0"}
+	if blockSize != tftpDefaultBlockSize {
+		options["blksize"] = strconv.Itoa(blockSize)
+	}
+
+	t := &tftpTransfer{
+		client:    c,
+		conn:      conn,
+		server:    serverAddr,
+		blockSize: tftpDefaultBlockSize,
+		tsize:     -1,
+		buf:       make([]byte, tftpMaxBlockSize+4),
+	}
+	return t.run(encodeTFTPRequest(tftpOpRRQ, filename, options), w)
+}
+
+// tftpTransfer is the state of a single download
+type tftpTransfer struct {
+	client *TFTPClient
+	conn   *net.UDPConn
+	// server is the address packets go to. After the first reply it is the
+	// server's transfer ID rather than port 69.
+	server    *net.UDPAddr
+	block     uint16
+	blockSize int
+	tsize     int64
+	buf       []byte
+}
+
+// run sends the request and receives the file
+func (t *tftpTransfer) run(request []byte, w io.Writer) (int64, error) {
+	var written int64
+	lastSent := request
+	first := true
+
+	for {
+		packet, from, err := t.exchange(lastSent, first)
+		if err != nil {
+			return written, err
+		}
+		if first {
+			// The server answers from a new port, its transfer ID
+			t.server = from
+			first = false
+		}
+
+		opcode := binary.BigEndian.Uint16(packet)
+		switch opcode {
+		case tftpOpOACK:
+			if err := t.applyOptions(packet[2:]); err != nil {
+				t.sendError(8, err.Error()) // Option negotiation failed (RFC 2347)
+				return written, err
+			}
+			lastSent = tftpAck(0)
+		case tftpOpDATA:
+			number := binary.BigEndian.Uint16(packet[2:4])
+			data := packet[4:]
+			if len(data) > t.blockSize {
+				return written, fmt.Errorf("TFTP block %d is %d bytes, larger than block size %d", number, len(data), t.blockSize)
+			}
+
+			n, err := w.Write(data)
+			written += int64(n)
+			if err != nil {
+				t.sendError(3, "disk full")
+				return written, fmt.Errorf("failed to write TFTP data: %w", err)
+			}
+			t.block = number
+			lastSent = tftpAck(t.block)
+
+			if len(data) < t.blockSize {
+				// The final block: acknowledge it without waiting for more
+				t.conn.WriteToUDP(lastSent, t.server)
+				if t.tsize >= 0 && written != t.tsize {
+					return written, fmt.Errorf("TFTP transfer size mismatch: got %d bytes, expected %d", written, t.tsize)
+				}
+				return written, nil
+			}
+		case tftpOpERROR:
+			return written, decodeTFTPError(packet)
+		default:
+			return written, fmt.Errorf("unexpected TFTP opcode %d", opcode)
+		}
+	}
+}
+
+// exchange sends a packet and waits for the reply, retransmitting on
+// timeout. Only the server the request went to may reply, and after the
+// first reply only from its transfer ID; other packets are answered with
+// an error and ignored.
+func (t *tftpTransfer) exchange(packet []byte, first bool) ([]byte, *net.UDPAddr, error) {
+	timeout := t.client.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	retries := t.client.Retries
+	if retries == 0 {
+		retries = 5
+	}
+
+	for attempt := 0; attempt <= retries; attempt++ {
+		if _, err := t.conn.WriteToUDP(packet, t.server); err != nil {
+			return nil, nil, fmt.Errorf("failed to send TFTP packet: %w", err)
+		}
+
+		deadline := time.Now().Add(timeout)
+		t.conn.SetReadDeadline(deadline)
+		for {
+			n, from, err := t.conn.ReadFromUDP(t.buf)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read TFTP packet: %w", err)
+			}
+			if !from.IP.Equal(t.server.IP) || (!first && from.Port != t.server.Port) {
+				t.conn.WriteToUDP(encodeTFTPError(5, "unknown transfer ID"), from)
+				continue
+			}
+			if n < 4 {
+				continue
+			}
+			if !first && t.stale(t.buf[:n]) {
+				continue
+			}
+			return t.buf[:n], from, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("TFTP server did not respond")
+}
+
+// stale reports whether packet repeats one already handled. A repeated
+// DATA block is acknowledged again, but the retransmit timer keeps
+// running: restarting it on every duplicate would let each duplicate
+// double the traffic (the Sorcerer's Apprentice bug, RFC 1123 section
+// 4.2.3.1).
+func (t *tftpTransfer) stale(packet []byte) bool {
+	switch binary.BigEndian.Uint16(packet) {
+	case tftpOpOACK:
+		return t.block != 0
+	case tftpOpDATA:
+		if binary.BigEndian.Uint16(packet[2:4]) == t.block+1 {
+			return false
+		}
+		t.conn.WriteToUDP(tftpAck(t.block), t.server)
+		return true
+	default:
+		return false
+	}
+}
+
+// applyOptions applies the options the server acknowledged in an OACK
+func (t *tftpTransfer) applyOptions(data []byte) error {
+	options, err := decodeTFTPOptions(data)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range options {
+		switch name {
+		case "blksize":
+			size, err := strconv.Atoi(value)
+			if err != nil || size < tftpMinBlockSize || size > tftpMaxBlockSize {
+				return fmt.Errorf("invalid blksize %q", value)
+			}
+			t.blockSize = size
+		case "tsize":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return fmt.Errorf("invalid tsize %q", value)
+			}
+			t.tsize = size
+		default:
+			return fmt.Errorf("unrequested option %q", name)
+		}
+	}
+	return nil
+}
+
+// sendError tells the server the transfer is aborted
+func (t *tftpTransfer) sendError(code uint16, message string) {
+	t.conn.WriteToUDP(encodeTFTPError(code, message), t.server)
+}
+
+// encodeTFTPRequest builds an RRQ or WRQ packet in octet mode
+func encodeTFTPRequest(opcode uint16, filename string, options map[string]string) []byte {
+	packet := binary.BigEndian.AppendUint16(nil, opcode)
+	packet = append(packet, filename...)
+	packet = append(packet, 0)
+	packet = append(packet, "octet"...)
+	packet = append(packet, 0)
+	// Fixed order keeps requests reproducible
+	for _, name := range []string{"blksize", "tsize"} {
+		if value, exists := options[name]; exists {
+			packet = append(packet, name...)
+			packet = append(packet, 0)
+			packet = append(packet, value...)
+			packet = append(packet, 0)
+		}
+	}
+	return packet
+}
+
+// decodeTFTPOptions decodes the NUL-terminated name/value pairs of a
+// request or OACK. Option names are case-insensitive.
+func decodeTFTPOptions(data []byte) (map[string]string, error) {
+	fields := bytes.Split(data, []byte{0})
+	if len(fields) == 0 || len(fields[len(fields)-1]) != 0 {
+		return nil, fmt.Errorf("TFTP options are not NUL-terminated")
+	}
+	fields = fields[:len(fields)-1]
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("TFTP option %q has no value", fields[len(fields)-1])
+	}
+
+	options := make(map[string]string)
+	for i := 0; i < len(fields); i += 2 {
+		options[strings.ToLower(string(fields[i]))] = string(fields[i+1])
+	}
+	return options, nil
+}
+
+// tftpAck builds an ACK packet
+func tftpAck(block uint16) []byte {
+	packet := binary.BigEndian.AppendUint16(nil, tftpOpACK)
+	return binary.BigEndian.AppendUint16(packet, block)
+}
+
+// encodeTFTPError builds an ERROR packet
+func encodeTFTPError(code uint16, message string) []byte {
+	packet := binary.BigEndian.AppendUint16(nil, tftpOpERROR)
+	packet = binary.BigEndian.AppendUint16(packet, code)
+	packet = append(packet, message...)
+	return append(packet, 0)
+}
+
+// decodeTFTPError decodes an ERROR packet
+func decodeTFTPError(packet []byte) error {
+	message, _, _ := bytes.Cut(packet[4:], []byte{0})
+	return &TFTPError{Code: binary.BigEndian.Uint16(packet[2:4]), Message: string(message)}
+}
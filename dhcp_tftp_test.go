@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testTFTPServer is a minimal read-only TFTP server for tests. With
+// ignoreOptions set it behaves like an RFC 1350 server without option
+// negotiation.
+type testTFTPServer struct {
+	conn          *net.UDPConn
+	files         map[string][]byte
+	ignoreOptions bool
+}
+
+func startTestTFTPServer(t *testing.T, files map[string][]byte, ignoreOptions bool) *testTFTPServer {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	s := &testTFTPServer{conn: conn, files: files, ignoreOptions: ignoreOptions}
+	go s.serve()
+	return s
+}
+
+func (s *testTFTPServer) addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *testTFTPServer) serve() {
+	buf := make([]byte, 1500)
+	for {
+		n, client, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if n < 2 || binary.BigEndian.Uint16(buf) != tftpOpRRQ {
+			continue
+		}
+		go s.transfer(append([]byte(nil), buf[2:n]...), client)
+	}
+}
+
+// transfer sends one file from a new transfer ID
+func (s *testTFTPServer) transfer(request []byte, client *net.UDPAddr) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	fields := bytes.Split(request, []byte{0})
+	data, exists := s.files[string(fields[0])]
+	if !exists {
+		conn.WriteToUDP(encodeTFTPError(1, "file not found"), client)
+		return
+	}
+	options, _ := decodeTFTPOptions(bytes.Join(fields[2:], []byte{0}))
+
+	blockSize := tftpDefaultBlockSize
+	send := func(packet []byte, expectBlock uint16) bool {
+		ack := make([]byte, 1500)
+		for attempt := 0; attempt < 3; attempt++ {
+			conn.WriteToUDP(packet, client)
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			n, _, err := conn.ReadFromUDP(ack)
+			if err != nil {
+				continue
+			}
+			if n == 4 && binary.BigEndian.Uint16(ack) == tftpOpACK && binary.BigEndian.Uint16(ack[2:]) == expectBlock {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !s.ignoreOptions && len(options) > 0 {
+		oack := binary.BigEndian.AppendUint16(nil, tftpOpOACK)
+		if value, exists := options["blksize"]; exists {
+			blockSize, _ = strconv.Atoi(value)
+			oack = append(oack, "blksize\x00"+value+"\x00"...)
+		}
+		if _, exists := options["tsize"]; exists {
+			oack = append(oack, "tsize\x00"+strconv.Itoa(len(data))+"\x00"...)
+		}
+		if !send(oack, 0) {
+			return
+		}
+	}
+
+	for block := uint16(1); ; block++ {
+		chunk := data[:min(blockSize, len(data))]
+		data = data[len(chunk):]
+		packet := binary.BigEndian.AppendUint16(nil, tftpOpDATA)
+		packet = binary.BigEndian.AppendUint16(packet, block)
+		if !send(append(packet, chunk...), block) || len(chunk) < blockSize {
+			return
+		}
+	}
+}
+
+func testFile(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+func TestTFTPFetch(t *testing.T) {
+	files := map[string][]byte{
+		"pxelinux.0": testFile(5000),
+		"exact.bin":  testFile(3 * 1468),
+		"empty":      {},
+	}
+
+	for _, ignoreOptions := range []bool{false, true} {
+		server := startTestTFTPServer(t, files, ignoreOptions)
+		client := &TFTPClient{Timeout: time.Second}
+
+		for name, want := range files {
+			var got bytes.Buffer
+			n, err := client.Fetch(server.addr(), name, &got)
+			if err != nil {
+				t.Fatalf("Fetch(%s, ignoreOptions=%v): %v", name, ignoreOptions, err)
+			}
+			if n != int64(len(want)) || !bytes.Equal(got.Bytes(), want) {
+				t.Fatalf("Fetch(%s, ignoreOptions=%v) returned %d bytes, want %d", name, ignoreOptions, n, len(want))
+			}
+		}
+	}
+}
+
+func TestTFTPFetchMissingFile(t *testing.T) {
+	server := startTestTFTPServer(t, map[string][]byte{}, false)
+
+	_, err := (&TFTPClient{Timeout: time.Second}).Fetch(server.addr(), "missing", &bytes.Buffer{})
+	var tftpErr *TFTPError
+	if !errors.As(err, &tftpErr) || tftpErr.Code != 1 {
+		t.Fatalf("expected TFTP error 1, got %v", err)
+	}
+}
+
+// tftpData builds a DATA packet
+func tftpData(block uint16, data []byte) []byte {
+	packet := binary.BigEndian.AppendUint16(nil, tftpOpDATA)
+	packet = binary.BigEndian.AppendUint16(packet, block)
+	return append(packet, data...)
+}
+
+func TestTFTPDuplicateDataKeepsTimer(t *testing.T) {
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer server.Close()
+
+	go func() {
+		buf := make([]byte, 1500)
+		_, client, err := server.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		// Send block 1, then keep repeating it without ever sending
+		// block 2
+		block := tftpData(1, testFile(tftpDefaultBlockSize))
+		for i := 0; i < 20; i++ {
+			server.WriteToUDP(block, client)
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+
+	start := time.Now()
+	client := &TFTPClient{BlockSize: tftpDefaultBlockSize, Timeout: 300 * time.Millisecond, Retries: 1}
+	if _, err := client.Fetch(server.LocalAddr().String(), "pxelinux.0", &bytes.Buffer{}); err == nil {
+		t.Fatal("expected the transfer to time out")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("duplicates kept the transfer alive for %s", elapsed)
+	}
+}
+
+func TestTFTPIgnoresRepliesFromOtherHosts(t *testing.T) {
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer server.Close()
+
+	rogue, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.2"), Port: 0})
+	if err != nil {
+		t.Skipf("no second loopback address: %v", err)
+	}
+	defer rogue.Close()
+
+	go func() {
+		buf := make([]byte, 1500)
+		_, client, err := server.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		rogue.WriteToUDP(tftpData(1, []byte("rogue")), client)
+		time.Sleep(50 * time.Millisecond)
+		server.WriteToUDP(tftpData(1, []byte("genuine")), client)
+	}()
+
+	var got bytes.Buffer
+	client := &TFTPClient{BlockSize: tftpDefaultBlockSize, Timeout: time.Second}
+	if _, err := client.Fetch(server.LocalAddr().String(), "pxelinux.0", &got); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if got.String() != "genuine" {
+		t.Fatalf("got %q, want the reply of the server the request went to", got.String())
+	}
+}
+
+func TestTFTPRequestEncoding(t *testing.T) {
+	got := encodeTFTPRequest(tftpOpRRQ, "boot.efi", map[string]string{"tsize": "0", "blksize": "1468"})
+	want := []byte("\x00\x01boot.efi\x00octet\x00blksize\x001468\x00tsize\x000\x00")
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	options, err := decodeTFTPOptions([]byte("BLKSIZE\x001024\x00tsize\x00200\x00"))
+	if err != nil || options["blksize"] != "1024" || options["tsize"] != "200" {
+		t.Fatalf("decodeTFTPOptions = %v, %v", options, err)
+	}
+	if _, err := decodeTFTPOptions([]byte("blksize\x00")); err == nil {
+		t.Fatal("expected error for option without value")
+	}
+}
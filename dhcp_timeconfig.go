@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Names of the drop-in fragments written for time synchronisation daemons
+const (
+	ChronySourcesFile = "dhcp-client.sources"
+	TimesyncdDropIn   = "50-dhcp-client.conf"
+)
+
+// TimeConfig selects where NTP configuration from the lease is written
+type TimeConfig struct {
+	// Request adds options 42, 100 and 101 to the parameter request list.
+	// It is implied when either directory is set.
+	Request bool
+	// ChronyDir is a chrony sourcedir, e.g. /etc/chrony/sources.d
+	ChronyDir string
+	// TimesyncdDir is a systemd-timesyncd drop-in directory, e.g.
+	// /etc/systemd/timesyncd.conf.d
+	TimesyncdDir string
+}
+
+// requested reports whether the time options should be requested
+func (cfg TimeConfig) requested() bool {
+	return cfg.Request || cfg.ChronyDir != "" || cfg.TimesyncdDir != ""
+}
+
+// ChronySources returns a chrony sources fragment for the lease's NTP
+// servers
+func (l *Lease) ChronySources() string {
+	var result strings.Builder
+
+	result.WriteString("# NTP servers from DHCP (option 42)\n")
+	for _, server := range l.NTPServers {
+		result.WriteString(fmt.Sprintf("server %s iburst\n", server))
+	}
+
+	return result.String()
+}
+
+// TimesyncdConfig returns a systemd-timesyncd drop-in for the lease's NTP
+// servers
+func (l *Lease) TimesyncdConfig() string {
+	servers := make([]string, len(l.NTPServers))
+	for i, server := range l.NTPServers {
+		servers[i] = server.String()
+	}
+	return fmt.Sprintf("# NTP servers from DHCP (option 42)\n[Time]\nNTP=%s\n", strings.Join(servers, " "))
+}
+
+// WriteChronySources writes the chrony fragment into dir. A lease without
+// NTP servers removes a fragment left by an earlier lease.
+func WriteChronySources(dir string, lease *Lease) error {
+	return writeDropIn(filepath.Join(dir, ChronySourcesFile), lease.ChronySources(), len(lease.NTPServers) > 0)
+}
+
+// WriteTimesyncdConfig writes the systemd-timesyncd drop-in into dir. A
+// lease without NTP servers removes a drop-in left by an earlier lease.
+func WriteTimesyncdConfig(dir string, lease *Lease) error {
+	return writeDropIn(filepath.Join(dir, TimesyncdDropIn), lease.TimesyncdConfig(), len(lease.NTPServers) > 0)
+}
+
+// writeDropIn atomically replaces path with content, or removes it when
+// keep is false
+func writeDropIn(path, content string, keep bool) error {
+	if !keep {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyTimeConfig writes the configured time synchronisation fragments
+func (c *DHCPClient) applyTimeConfig() error {
+	if dir := c.config.Time.ChronyDir; dir != "" {
+		if err := WriteChronySources(dir, c.lease); err != nil {
+			return err
+		}
+	}
+	if dir := c.config.Time.TimesyncdDir; dir != "" {
+		if err := WriteTimesyncdConfig(dir, c.lease); err != nil {
+			return err
+		}
+	}
+	return nil
+}
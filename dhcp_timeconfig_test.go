@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLeaseTimeOptions(t *testing.T) {
+	msg := &DHCPMessage{Options: map[byte][]byte{
+		OptionNTPServers:     {10, 0, 0, 1, 10, 0, 0, 2},
+		OptionPOSIXTimeZone:  []byte("CET-1CEST,M3.5.0,M10.5.0/3"),
+		OptionTZDatabaseName: []byte("Europe/Zurich"),
+	}}
+
+	lease, err := NewLease(msg)
+	if err != nil {
+		t.Fatalf("NewLease: %v", err)
+	}
+	if len(lease.NTPServers) != 2 || lease.NTPServers[1] != netip.MustParseAddr("10.0.0.2") {
+		t.Fatalf("NTP servers = %v", lease.NTPServers)
+	}
+	if lease.POSIXTimeZone != "CET-1CEST,M3.5.0,M10.5.0/3" || lease.TimeZone != "Europe/Zurich" {
+		t.Fatalf("time zones = %q, %q", lease.POSIXTimeZone, lease.TimeZone)
+	}
+}
+
+func TestWriteTimeDropIns(t *testing.T) {
+	dir := t.TempDir()
+	lease := &Lease{NTPServers: []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2")}}
+
+	if err := WriteChronySources(dir, lease); err != nil {
+		t.Fatalf("WriteChronySources: %v", err)
+	}
+	if err := WriteTimesyncdConfig(dir, lease); err != nil {
+		t.Fatalf("WriteTimesyncdConfig: %v", err)
+	}
+
+	chrony, _ := os.ReadFile(filepath.Join(dir, ChronySourcesFile))
+	if !bytes.Contains(chrony, []byte("server 10.0.0.1 iburst\nserver 10.0.0.2 iburst\n")) {
+		t.Errorf("chrony sources:\n%s", chrony)
+	}
+	timesyncd, _ := os.ReadFile(filepath.Join(dir, TimesyncdDropIn))
+	if !bytes.Contains(timesyncd, []byte("[Time]\nNTP=10.0.0.1 10.0.0.2\n")) {
+		t.Errorf("timesyncd drop-in:\n%s", timesyncd)
+	}
+
+	// A lease without NTP servers removes the stale fragments
+	if err := WriteChronySources(dir, &Lease{}); err != nil {
+		t.Fatalf("WriteChronySources: %v", err)
+	}
+	if err := WriteTimesyncdConfig(dir, &Lease{}); err != nil {
+		t.Fatalf("WriteTimesyncdConfig: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Fatalf("expected empty directory, found %d entries", len(entries))
+	}
+
+	if err := WriteChronySources(filepath.Join(dir, "missing"), lease); err == nil {
+		t.Fatal("expected error for missing directory")
+	} else if _, statErr := os.Stat(filepath.Join(dir, "missing")); !errors.Is(statErr, os.ErrNotExist) {
+		t.Fatalf("unexpected directory created: %v", statErr)
+	}
+}
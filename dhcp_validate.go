@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// DefaultMinLeaseTime is the shortest lease time accepted without a
+// LeaseCheckLeaseTime problem when the policy sets no floor
+const DefaultMinLeaseTime = time.Minute
+
+// LeaseCheck identifies a sanity check applied to offers and leases
+type LeaseCheck uint8
+
+// Lease checks
+const (
+	LeaseCheckAddress    LeaseCheck = iota // yiaddr is zero, broadcast, multicast or loopback
+	LeaseCheckSubnetMask                   // The subnet mask is not contiguous
+	LeaseCheckRouters                      // A router is outside the assigned subnet
+	LeaseCheckLeaseTime                    // The lease time is missing or below the floor
+	LeaseCheckTimers                       // T1, T2 and the lease time are out of order
+	LeaseCheckServerID                     // The server identifier is missing
+)
+
+// String returns the name of the check
+func (c LeaseCheck) String() string {
+	switch c {
+	case LeaseCheckAddress:
+		return "address"
+	case LeaseCheckSubnetMask:
+		return "subnet-mask"
+	case LeaseCheckRouters:
+		return "routers"
+	case LeaseCheckLeaseTime:
+		return "lease-time"
+	case LeaseCheckTimers:
+		return "timers"
+	case LeaseCheckServerID:
+		return "server-id"
+	default:
+		return fmt.Sprintf("LeaseCheck(%d)", uint8(c))
+	}
+}
+
+// ValidationAction is what the client does when a check fails
+type ValidationAction uint8
+
+// Validation actions
+const (
+	ValidationDefault ValidationAction = iota // The check's default action
+	ValidationReject                          // Ignore the offer or refuse the lease
+	ValidationWarn                            // Log the problem and continue
+	ValidationIgnore                          // Skip the check
+)
+
+// defaultValidationActions rejects replies the client cannot use and
+// warns about questionable but workable ones
+var defaultValidationActions = map[LeaseCheck]ValidationAction{
+	LeaseCheckAddress:    ValidationReject,
+	LeaseCheckSubnetMask: ValidationReject,
+	LeaseCheckRouters:    ValidationWarn,
+	LeaseCheckLeaseTime:  ValidationWarn,
+	LeaseCheckTimers:     ValidationWarn,
+	LeaseCheckServerID:   ValidationReject,
+}
+
+// ValidationPolicy configures the sanity checks applied to offers and
+// leases. The zero value uses the default action of every check.
+type ValidationPolicy struct {
+	// Actions overrides the action of individual checks
+	Actions map[LeaseCheck]ValidationAction
+	// MinLeaseTime is the shortest acceptable lease time. Zero means
+	// DefaultMinLeaseTime.
+	MinLeaseTime time.Duration
+}
+
+// action returns the action configured for check
+func (p ValidationPolicy) action(check LeaseCheck) ValidationAction {
+	if action := p.Actions[check]; action != ValidationDefault {
+		return action
+	}
+	return defaultValidationActions[check]
+}
+
+// LeaseProblem is a failed check
+type LeaseProblem struct {
+	Check   LeaseCheck
+	Message string
+}
+
+func (p LeaseProblem) Error() string {
+	return fmt.Sprintf("%s: %s", p.Check, p.Message)
+}
+
+// LeaseValidationError lists the problems that rejected a lease
+type LeaseValidationError struct {
+	Problems []LeaseProblem
+}
+
+func (e *LeaseValidationError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Error()
+	}
+	return "invalid lease: " + strings.Join(messages, "; ")
+}
+
+// Validate checks a lease parsed from a DHCPOFFER or DHCPACK. Problems
+// whose action is reject are returned as a *LeaseValidationError; the
+// others are returned as warnings.
+func (p ValidationPolicy) Validate(lease *Lease) (warnings []LeaseProblem, err error) {
+	var rejected []LeaseProblem
+	for _, problem := range checkLease(lease, p.minLeaseTime()) {
+		switch p.action(problem.Check) {
+		case ValidationReject:
+			rejected = append(rejected, problem)
+		case ValidationWarn:
+			warnings = append(warnings, problem)
+		}
+	}
+	if len(rejected) > 0 {
+		return warnings, &LeaseValidationError{Problems: rejected}
+	}
+	return warnings, nil
+}
+
+// minLeaseTime returns the configured lease time floor
+func (p ValidationPolicy) minLeaseTime() time.Duration {
+	if p.MinLeaseTime == 0 {
+		return DefaultMinLeaseTime
+	}
+	return p.MinLeaseTime
+}
+
+// checkLease runs every check and returns the problems found
+func checkLease(lease *Lease, minLeaseTime time.Duration) []LeaseProblem {
+	var problems []LeaseProblem
+	report := func(check LeaseCheck, format string, args ...any) {
+		problems = append(problems, LeaseProblem{Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	ip := lease.IP
+	usable := false
+	switch {
+	case !ip.IsValid() || ip.IsUnspecified():
+		report(LeaseCheckAddress, "no address assigned")
+	case ip == netip.AddrFrom4([4]byte{255, 255, 255, 255}):
+		report(LeaseCheckAddress, "broadcast address %s assigned", ip)
+	case ip.IsMulticast():
+		report(LeaseCheckAddress, "multicast address %s assigned", ip)
+	case ip.IsLoopback():
+		report(LeaseCheckAddress, "loopback address %s assigned", ip)
+	default:
+		usable = true
+	}
+
+	var subnet netip.Prefix
+	if lease.SubnetMask.IsValid() {
+		prefixLen, ok := maskBits(lease.SubnetMask)
+		if !ok {
+			report(LeaseCheckSubnetMask, "subnet mask %s is not contiguous", lease.SubnetMask)
+		} else if usable {
+			subnet = netip.PrefixFrom(ip, prefixLen).Masked()
+			if prefixLen <= 30 && (ip == subnet.Addr() || ip == lastAddr(subnet)) {
+				report(LeaseCheckAddress, "address %s is the network or broadcast address of %s", ip, subnet)
+			}
+		}
+	}
+
+	if subnet.IsValid() {
+		for _, router := range lease.Routers {
+			if !subnet.Contains(router) {
+				report(LeaseCheckRouters, "router %s is outside the subnet %s", router, subnet)
+			}
+		}
+	}
+
+	if lease.LeaseTime == 0 {
+		report(LeaseCheckLeaseTime, "no lease time")
+	} else if lease.LeaseTime < minLeaseTime {
+		report(LeaseCheckLeaseTime, "lease time %s is shorter than %s", lease.LeaseTime, minLeaseTime)
+	}
+
+	if lease.LeaseTime != 0 && lease.LeaseTime != InfiniteLeaseTime {
+		t1, t2 := lease.RenewalTime, lease.RebindingTime
+		if t1 != 0 && t2 != 0 && t1 >= t2 {
+			report(LeaseCheckTimers, "renewal time %s is not before rebinding time %s", t1, t2)
+		}
+		if t1 >= lease.LeaseTime {
+			report(LeaseCheckTimers, "renewal time %s is not before the lease time %s", t1, lease.LeaseTime)
+		}
+		if t2 >= lease.LeaseTime {
+			report(LeaseCheckTimers, "rebinding time %s is not before the lease time %s", t2, lease.LeaseTime)
+		}
+	}
+
+	if !lease.ServerID.IsValid() {
+		report(LeaseCheckServerID, "no server identifier")
+	}
+
+	return problems
+}
+
+// maskBits returns the prefix length of a contiguous subnet mask
+func maskBits(mask netip.Addr) (int, bool) {
+	m := mask.As4()
+	value := binary.BigEndian.Uint32(m[:])
+	ones := bits.LeadingZeros32(^value)
+	return ones, value<<ones == 0
+}
+
+// lastAddr returns the highest address of an IPv4 prefix
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	a := prefix.Addr().As4()
+	return uint32ToAddr(binary.BigEndian.Uint32(a[:]) | (1<<(32-prefix.Bits()) - 1))
+}
+
+// validateReply parses and checks a DHCPOFFER
+func (c *DHCPClient) validateReply(msg *DHCPMessage) error {
+	lease, err := c.newLease(msg)
+	if err != nil {
+		return err
+	}
+	return c.validateLease(msg, lease)
+}
+
+// validateLease checks the lease parsed from msg, printing warnings.
+// Replies that only signal IPv6-only operation carry no address and are
+// not checked.
+func (c *DHCPClient) validateLease(msg *DHCPMessage, lease *Lease) error {
+	if c.v6OnlyPreferred(msg) {
+		return nil
+	}
+
+	warnings, err := c.config.Validation.Validate(lease)
+	for _, warning := range warnings {
+		fmt.Printf("Warning: %v\n", warning)
+	}
+	return err
+}
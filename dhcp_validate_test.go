@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+// validLease returns a lease that passes every check
+func validLease() *Lease {
+	return &Lease{
+		IP:            netip.MustParseAddr("192.168.1.20"),
+		SubnetMask:    netip.MustParseAddr("255.255.255.0"),
+		ServerID:      netip.MustParseAddr("192.168.1.1"),
+		Routers:       []netip.Addr{netip.MustParseAddr("192.168.1.1")},
+		LeaseTime:     time.Hour,
+		RenewalTime:   30 * time.Minute,
+		RebindingTime: 52 * time.Minute,
+	}
+}
+
+func TestCheckLease(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Lease)
+		want   []LeaseCheck
+	}{
+		{"valid", func(*Lease) {}, nil},
+		{"zero address", func(l *Lease) { l.IP = netip.IPv4Unspecified() }, []LeaseCheck{LeaseCheckAddress}},
+		{"broadcast", func(l *Lease) { l.IP = netip.MustParseAddr("255.255.255.255"); l.SubnetMask = netip.Addr{} }, []LeaseCheck{LeaseCheckAddress}},
+		{"multicast", func(l *Lease) { l.IP = netip.MustParseAddr("224.0.0.1"); l.SubnetMask = netip.Addr{} }, []LeaseCheck{LeaseCheckAddress}},
+		{"loopback", func(l *Lease) { l.IP = netip.MustParseAddr("127.0.0.5"); l.SubnetMask = netip.Addr{} }, []LeaseCheck{LeaseCheckAddress}},
+		{"subnet broadcast", func(l *Lease) { l.IP = netip.MustParseAddr("192.168.1.255") }, []LeaseCheck{LeaseCheckAddress}},
+		{"network address", func(l *Lease) { l.IP = netip.MustParseAddr("192.168.1.0") }, []LeaseCheck{LeaseCheckAddress}},
+		{"point-to-point /31", func(l *Lease) {
+			l.IP = netip.MustParseAddr("192.168.1.0")
+			l.SubnetMask = netip.MustParseAddr("255.255.255.254")
+			l.Routers = []netip.Addr{netip.MustParseAddr("192.168.1.1")}
+		}, nil},
+		{"non-contiguous mask", func(l *Lease) { l.SubnetMask = netip.MustParseAddr("255.0.255.0") }, []LeaseCheck{LeaseCheckSubnetMask}},
+		{"router outside subnet", func(l *Lease) { l.Routers = append(l.Routers, netip.MustParseAddr("10.0.0.1")) }, []LeaseCheck{LeaseCheckRouters}},
+		{"short lease", func(l *Lease) { l.LeaseTime, l.RenewalTime, l.RebindingTime = 30*time.Second, 0, 0 }, []LeaseCheck{LeaseCheckLeaseTime}},
+		{"no lease time", func(l *Lease) { l.LeaseTime = 0 }, []LeaseCheck{LeaseCheckLeaseTime}},
+		{"T1 after T2", func(l *Lease) { l.RenewalTime = 55 * time.Minute }, []LeaseCheck{LeaseCheckTimers}},
+		{"T2 after lease", func(l *Lease) { l.RebindingTime = 2 * time.Hour }, []LeaseCheck{LeaseCheckTimers}},
+		{"infinite lease", func(l *Lease) { l.LeaseTime = InfiniteLeaseTime }, nil},
+		{"no server identifier", func(l *Lease) { l.ServerID = netip.Addr{} }, []LeaseCheck{LeaseCheckServerID}},
+	}
+
+	for _, tt := range tests {
+		lease := validLease()
+		tt.modify(lease)
+		problems := checkLease(lease, DefaultMinLeaseTime)
+
+		var got []LeaseCheck
+		for _, problem := range problems {
+			got = append(got, problem.Check)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got problems %v, want checks %v", tt.name, problems, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got problems %v, want checks %v", tt.name, problems, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestValidationPolicy(t *testing.T) {
+	lease := validLease()
+	lease.IP = netip.IPv4Unspecified()
+	lease.LeaseTime, lease.RenewalTime, lease.RebindingTime = 30*time.Second, 0, 0
+
+	warnings, err := ValidationPolicy{}.Validate(lease)
+	var validationErr *LeaseValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Problems) != 1 || validationErr.Problems[0].Check != LeaseCheckAddress {
+		t.Fatalf("default policy: got error %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Check != LeaseCheckLeaseTime {
+		t.Fatalf("default policy: got warnings %v", warnings)
+	}
+
+	policy := ValidationPolicy{
+		Actions: map[LeaseCheck]ValidationAction{
+			LeaseCheckAddress:   ValidationIgnore,
+			LeaseCheckLeaseTime: ValidationReject,
+		},
+		MinLeaseTime: 10 * time.Second,
+	}
+	if warnings, err := policy.Validate(lease); err != nil || len(warnings) != 0 {
+		t.Fatalf("a 30s lease above a 10s floor: got warnings %v, error %v", warnings, err)
+	}
+
+	policy.MinLeaseTime = 5 * time.Minute
+	if _, err := policy.Validate(lease); !errors.As(err, &validationErr) || validationErr.Problems[0].Check != LeaseCheckLeaseTime {
+		t.Fatalf("a 30s lease below a 5m floor: got %v", err)
+	}
+}
+
+func TestInvalidAckRejected(t *testing.T) {
+	ack := &DHCPMessage{
+		OpCode: BootReply,
+		YourIP: 0,
+		Options: map[byte][]byte{
+			OptionDHCPMessageType:    {DHCPAck},
+			OptionServerIdentifier:   {192, 168, 1, 1},
+			OptionIPAddressLeaseTime: {0, 0, 0x0e, 0x10},
+		},
+	}
+
+	client := &DHCPClient{}
+	if err := client.handleResponse(ack); err == nil {
+		t.Fatal("expected a DHCPACK without an address to be rejected")
+	}
+	if client.Lease() != nil || client.State() == StateBound {
+		t.Fatalf("client bound to a rejected lease %v", client.Lease())
+	}
+
+	client.config.Validation.Actions = map[LeaseCheck]ValidationAction{LeaseCheckAddress: ValidationWarn}
+	if err := client.handleResponse(ack); err != nil {
+		t.Fatalf("handleResponse with warnings only: %v", err)
+	}
+}
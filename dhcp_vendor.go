@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// VendorSetting is a single named setting decoded from option 43
+type VendorSetting struct {
+	Name  string
+	Value string
+}
+
+// VendorInfo is the decoded content of option 43 (Vendor-Specific Information)
+type VendorInfo struct {
+	Vendor   string
+	Settings []VendorSetting
+}
+
+// Add appends a setting to the vendor information
+func (v *VendorInfo) Add(name, format string, args ...interface{}) {
+	v.Settings = append(v.Settings, VendorSetting{Name: name, Value: fmt.Sprintf(format, args...)})
+}
+
+// String returns a human-readable representation of the vendor settings
+func (v *VendorInfo) String() string {
+	var parts []string
+	for _, setting := range v.Settings {
+		parts = append(parts, fmt.Sprintf("%s=%s", setting.Name, setting.Value))
+	}
+	return fmt.Sprintf("%s: %s", v.Vendor, strings.Join(parts, ", "))
+}
+
+// VendorDecoder decodes the option 43 payload for a particular vendor
+type VendorDecoder func(data []byte) (*VendorInfo, error)
+
+type vendorDecoderEntry struct {
+	prefix  string
+	decoder VendorDecoder
+}
+
+// vendorDecodersMu guards vendorDecoders, so decoders can be registered
+// while clients decode leases
+var vendorDecodersMu sync.RWMutex
+
+// vendorDecoders holds the registered decoders, keyed on a prefix of the
+// vendor class identifier (option 60)
+var vendorDecoders = []vendorDecoderEntry{
+	{prefix: "PXEClient", decoder: decodePXEVendorInfo},
+	{prefix: "Cisco AP", decoder: decodeCiscoAPVendorInfo},
+	{prefix: "ArubaAP", decoder: decodeArubaAPVendorInfo},
+	{prefix: "MSFT", decoder: decodeMicrosoftVendorInfo},
+}
+
+// RegisterVendorDecoder registers a decoder for option 43 payloads sent to
+// clients whose vendor class identifier starts with prefix. Later
+// registrations take precedence over earlier ones for the same prefix. It
+// is safe to call concurrently with lease decoding.
+func RegisterVendorDecoder(prefix string, decoder VendorDecoder) {
+	vendorDecodersMu.Lock()
+	defer vendorDecodersMu.Unlock()
+	vendorDecoders = append(vendorDecoders, vendorDecoderEntry{prefix: prefix, decoder: decoder})
+}
+
+// lookupVendorDecoder returns the decoder with the longest prefix matching
+// the vendor class identifier, or nil if none matches
+func lookupVendorDecoder(vendorClass string) VendorDecoder {
+	vendorDecodersMu.RLock()
+	defer vendorDecodersMu.RUnlock()
+
+	var best VendorDecoder
+	bestLength := -1
+	for _, entry := range vendorDecoders {
+		if strings.HasPrefix(vendorClass, entry.prefix) && len(entry.prefix) >= bestLength {
+			best = entry.decoder
+			bestLength = len(entry.prefix)
+		}
+	}
+	return best
+}
+
+// DecodeVendorSpecific decodes option 43 using the decoder registered for
+// the vendor class identifier. Payloads from unknown vendors are decoded
+// as generic sub-options.
+func DecodeVendorSpecific(vendorClass string, data []byte) (*VendorInfo, error) {
+	if decoder := lookupVendorDecoder(vendorClass); decoder != nil {
+		return decoder(data)
+	}
+	return decodeGenericVendorInfo(data)
+}
+
+// decodeGenericVendorInfo decodes option 43 as RFC 2132 encapsulated
+// sub-options without interpreting their values
+func decodeGenericVendorInfo(data []byte) (*VendorInfo, error) {
+	subOptions, err := DecodeSubOptions(data, true)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &VendorInfo{Vendor: "Unknown vendor"}
+	for _, subOption := range subOptions {
+		info.Add(fmt.Sprintf("Sub-option %d", subOption.Code), "%s", opaqueString(subOption.Data))
+	}
+	return info, nil
+}
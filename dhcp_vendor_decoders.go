@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// PXE vendor sub-option codes (PXE specification 2.1)
+const (
+	PXEMTFTPIP          = 1
+	PXEMTFTPClientPort  = 2
+	PXEMTFTPServerPort  = 3
+	PXEMTFTPTimeout     = 4
+	PXEMTFTPDelay       = 5
+	PXEDiscoveryControl = 6
+	PXEDiscoveryMcast   = 7
+	PXEBootServers      = 8
+	PXEBootMenu         = 9
+	PXEMenuPrompt       = 10
+	PXEBootItem         = 71
+)
+
+// Cisco lightweight access point sub-option carrying controller addresses
+const CiscoAPControllerList = 241
+
+// Microsoft vendor sub-option codes (vendor class "MSFT 5.0")
+const (
+	MicrosoftDisableNetBIOS      = 1
+	MicrosoftReleaseOnShutdown   = 2
+	MicrosoftDefaultRouterMetric = 3
+)
+
+// decodePXEVendorInfo decodes PXE boot server discovery sub-options
+func decodePXEVendorInfo(data []byte) (*VendorInfo, error) {
+	subOptions, err := DecodeSubOptions(data, true)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &VendorInfo{Vendor: "PXE"}
+	for _, subOption := range subOptions {
+		value := subOption.Data
+		switch subOption.Code {
+		case PXEMTFTPIP, PXEDiscoveryMcast:
+			if len(value) != 4 {
+				return nil, fmt.Errorf("PXE sub-option %d must be 4 bytes, got %d", subOption.Code, len(value))
+			}
+			name := "MTFTP IP"
+			if subOption.Code == PXEDiscoveryMcast {
+				name = "Discovery Multicast Address"
+			}
+			info.Add(name, "%s", netip.AddrFrom4([4]byte(value)))
+		case PXEMTFTPClientPort, PXEMTFTPServerPort:
+			if len(value) != 2 {
+				return nil, fmt.Errorf("PXE sub-option %d must be 2 bytes, got %d", subOption.Code, len(value))
+			}
+			name := "MTFTP Client Port"
+			if subOption.Code == PXEMTFTPServerPort {
+				name = "MTFTP Server Port"
+			}
+			info.Add(name, "%d", binary.BigEndian.Uint16(value))
+		case PXEMTFTPTimeout, PXEMTFTPDelay:
+			if len(value) != 1 {
+				return nil, fmt.Errorf("PXE sub-option %d must be 1 byte, got %d", subOption.Code, len(value))
+			}
+			name := "MTFTP Timeout"
+			if subOption.Code == PXEMTFTPDelay {
+				name = "MTFTP Delay"
+			}
+			info.Add(name, "%d seconds", value[0])
+		case PXEDiscoveryControl:
+			if len(value) != 1 {
+				return nil, fmt.Errorf("PXE discovery control must be 1 byte, got %d", len(value))
+			}
+			info.Add("Discovery Control", "%s", pxeDiscoveryControlString(value[0]))
+		case PXEBootServers:
+			servers, err := decodePXEBootServers(value)
+			if err != nil {
+				return nil, err
+			}
+			info.Add("Boot Servers", "%s", servers)
+		case PXEBootMenu:
+			menu, err := decodePXEBootMenu(value)
+			if err != nil {
+				return nil, err
+			}
+			info.Add("Boot Menu", "%s", menu)
+		case PXEMenuPrompt:
+			if len(value) < 1 {
+				return nil, fmt.Errorf("PXE menu prompt is empty")
+			}
+			info.Add("Menu Prompt", "'%s' (timeout %d seconds)", string(value[1:]), value[0])
+		case PXEBootItem:
+			if len(value) != 4 {
+				return nil, fmt.Errorf("PXE boot item must be 4 bytes, got %d", len(value))
+			}
+			info.Add("Boot Item", "type %d layer %d", binary.BigEndian.Uint16(value[:2]), binary.BigEndian.Uint16(value[2:]))
+		default:
+			info.Add(fmt.Sprintf("Sub-option %d", subOption.Code), "%s", opaqueString(value))
+		}
+	}
+
+	return info, nil
+}
+
+func pxeDiscoveryControlString(control byte) string {
+	var flags []string
+	if control&0x01 != 0 {
+		flags = append(flags, "no broadcast discovery")
+	}
+	if control&0x02 != 0 {
+		flags = append(flags, "no multicast discovery")
+	}
+	if control&0x04 != 0 {
+		flags = append(flags, "boot server list only")
+	}
+	if control&0x08 != 0 {
+		flags = append(flags, "download boot file directly")
+	}
+	if len(flags) == 0 {
+		return "0x00"
+	}
+	return fmt.Sprintf("0x%02x (%s)", control, strings.Join(flags, ", "))
+}
+
+func decodePXEBootServers(data []byte) (string, error) {
+	var servers []string
+	for i := 0; i < len(data); {
+		if i+3 > len(data) {
+			return "", fmt.Errorf("truncated PXE boot server entry at offset %d", i)
+		}
+		serverType := binary.BigEndian.Uint16(data[i : i+2])
+		count := int(data[i+2])
+		i += 3
+		if i+count*4 > len(data) {
+			return "", fmt.Errorf("PXE boot server type %d lists %d addresses but only %d bytes remain", serverType, count, len(data)-i)
+		}
+
+		var addrs []netip.Addr
+		for j := 0; j < count; j++ {
+			addrs = append(addrs, netip.AddrFrom4([4]byte(data[i:i+4])))
+			i += 4
+		}
+		servers = append(servers, fmt.Sprintf("type %d: %s", serverType, joinAddrs(addrs)))
+	}
+	return strings.Join(servers, "; "), nil
+}
+
+func decodePXEBootMenu(data []byte) (string, error) {
+	var items []string
+	for i := 0; i < len(data); {
+		if i+3 > len(data) {
+			return "", fmt.Errorf("truncated PXE boot menu entry at offset %d", i)
+		}
+		serverType := binary.BigEndian.Uint16(data[i : i+2])
+		length := int(data[i+2])
+		i += 3
+		if i+length > len(data) {
+			return "", fmt.Errorf("truncated PXE boot menu description at offset %d", i)
+		}
+		items = append(items, fmt.Sprintf("%d='%s'", serverType, string(data[i:i+length])))
+		i += length
+	}
+	return strings.Join(items, ", "), nil
+}
+
+// decodeCiscoAPVendorInfo decodes the wireless LAN controller list sent to
+// Cisco lightweight access points
+func decodeCiscoAPVendorInfo(data []byte) (*VendorInfo, error) {
+	subOptions, err := DecodeSubOptions(data, true)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &VendorInfo{Vendor: "Cisco AP"}
+	for _, subOption := range subOptions {
+		if subOption.Code != CiscoAPControllerList {
+			info.Add(fmt.Sprintf("Sub-option %d", subOption.Code), "%s", opaqueString(subOption.Data))
+			continue
+		}
+		if len(subOption.Data) == 0 || len(subOption.Data)%4 != 0 {
+			return nil, fmt.Errorf("Cisco controller list must be a multiple of 4 bytes, got %d", len(subOption.Data))
+		}
+		for i := 0; i < len(subOption.Data); i += 4 {
+			info.Add("Controller", "%s", netip.AddrFrom4([4]byte(subOption.Data[i:i+4])))
+		}
+	}
+	return info, nil
+}
+
+// decodeArubaAPVendorInfo decodes the controller addresses sent to Aruba
+// access points, which use a plain comma-separated text payload
+func decodeArubaAPVendorInfo(data []byte) (*VendorInfo, error) {
+	if !isPrintable(data) {
+		return nil, fmt.Errorf("Aruba controller list is not text")
+	}
+
+	info := &VendorInfo{Vendor: "Aruba AP"}
+	for _, field := range strings.Split(string(data), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		addr, err := netip.ParseAddr(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Aruba controller address %q: %w", field, err)
+		}
+		info.Add("Controller", "%s", addr)
+	}
+	return info, nil
+}
+
+// decodeMicrosoftVendorInfo decodes the vendor options Windows DHCP servers
+// send to Windows clients
+func decodeMicrosoftVendorInfo(data []byte) (*VendorInfo, error) {
+	subOptions, err := DecodeSubOptions(data, true)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &VendorInfo{Vendor: "Microsoft"}
+	for _, subOption := range subOptions {
+		var name string
+		switch subOption.Code {
+		case MicrosoftDisableNetBIOS:
+			name = "NetBIOS over TCP/IP"
+		case MicrosoftReleaseOnShutdown:
+			name = "Release Lease On Shutdown"
+		case MicrosoftDefaultRouterMetric:
+			name = "Default Router Metric Base"
+		default:
+			info.Add(fmt.Sprintf("Sub-option %d", subOption.Code), "%s", opaqueString(subOption.Data))
+			continue
+		}
+
+		if len(subOption.Data) != 4 {
+			return nil, fmt.Errorf("Microsoft sub-option %d must be 4 bytes, got %d", subOption.Code, len(subOption.Data))
+		}
+		value := binary.BigEndian.Uint32(subOption.Data)
+
+		switch subOption.Code {
+		case MicrosoftDisableNetBIOS:
+			switch value {
+			case 1:
+				info.Add(name, "enabled")
+			case 2:
+				info.Add(name, "disabled")
+			default:
+				info.Add(name, "default (%d)", value)
+			}
+		case MicrosoftReleaseOnShutdown:
+			info.Add(name, "%t", value&1 != 0)
+		default:
+			info.Add(name, "%d", value)
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDecodeVendorSpecificDispatchesOnVendorClass(t *testing.T) {
+	tests := []struct {
+		vendorClass string
+		data        []byte
+		vendor      string
+		want        []string
+	}{
+		{
+			vendorClass: "PXEClient:Arch:00000:UNDI:002001",
+			data: []byte{
+				PXEDiscoveryControl, 1, 0x08,
+				PXEBootServers, 7, 0x80, 0x00, 1, 10, 0, 0, 5,
+				PXEMenuPrompt, 5, 3, 'B', 'o', 'o', 't',
+				255,
+			},
+			vendor: "PXE",
+			want:   []string{"download boot file directly", "type 32768: 10.0.0.5", "'Boot' (timeout 3 seconds)"},
+		},
+		{
+			vendorClass: "Cisco AP c3700",
+			data:        []byte{CiscoAPControllerList, 8, 10, 1, 1, 10, 10, 1, 1, 11},
+			vendor:      "Cisco AP",
+			want:        []string{"Controller=10.1.1.10", "Controller=10.1.1.11"},
+		},
+		{
+			vendorClass: "ArubaAP",
+			data:        []byte("192.168.10.2, 192.168.10.3"),
+			vendor:      "Aruba AP",
+			want:        []string{"Controller=192.168.10.2", "Controller=192.168.10.3"},
+		},
+		{
+			vendorClass: "MSFT 5.0",
+			data:        []byte{MicrosoftDisableNetBIOS, 4, 0, 0, 0, 2, MicrosoftReleaseOnShutdown, 4, 0, 0, 0, 1},
+			vendor:      "Microsoft",
+			want:        []string{"NetBIOS over TCP/IP=disabled", "Release Lease On Shutdown=true"},
+		},
+		{
+			vendorClass: "",
+			data:        []byte{1, 2, 0xca, 0xfe},
+			vendor:      "Unknown vendor",
+			want:        []string{"Sub-option 1=ca:fe"},
+		},
+	}
+
+	for _, tt := range tests {
+		info, err := DecodeVendorSpecific(tt.vendorClass, tt.data)
+		if err != nil {
+			t.Errorf("%q: %v", tt.vendorClass, err)
+			continue
+		}
+		if info.Vendor != tt.vendor {
+			t.Errorf("%q: got vendor %q, want %q", tt.vendorClass, info.Vendor, tt.vendor)
+		}
+		got := info.String()
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%q: %q does not contain %q", tt.vendorClass, got, want)
+			}
+		}
+	}
+}
+
+func TestGenericVendorInfoHonoursPadAndEnd(t *testing.T) {
+	info, err := DecodeVendorSpecific("", []byte{0, 0, 1, 1, 0xaa, 255, 2, 1, 0xbb})
+	if err != nil {
+		t.Fatalf("DecodeVendorSpecific: %v", err)
+	}
+	if len(info.Settings) != 1 || !strings.Contains(info.String(), "Sub-option 1=aa") {
+		t.Fatalf("unexpected settings %q", info.String())
+	}
+}
+
+func TestRegisterVendorDecoderPrefersLongestPrefix(t *testing.T) {
+	saved := vendorDecoders
+	defer func() { vendorDecoders = saved }()
+
+	RegisterVendorDecoder("PXEClient:Arch:00007", func(data []byte) (*VendorInfo, error) {
+		return &VendorInfo{Vendor: "UEFI PXE"}, nil
+	})
+
+	info, err := DecodeVendorSpecific("PXEClient:Arch:00007:UNDI:003016", nil)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if info.Vendor != "UEFI PXE" {
+		t.Fatalf("got vendor %q, want the more specific decoder", info.Vendor)
+	}
+}
+
+func TestRegisterVendorDecoderWhileDecoding(t *testing.T) {
+	saved := vendorDecoders
+	defer func() { vendorDecoders = saved }()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			RegisterVendorDecoder(fmt.Sprintf("Vendor%d", i), decodeGenericVendorInfo)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if _, err := DecodeVendorSpecific("Cisco AP c3700", nil); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestLeaseDecodesVendorSpecificForSentVendorClass(t *testing.T) {
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0x11, 0x22, 0x33, 0x44, 0x55}, ClientConfig{VendorClass: "Cisco AP c3700"})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	// The server does not echo option 60
+	ack := &DHCPMessage{Options: map[byte][]byte{
+		OptionVendorSpecific: {CiscoAPControllerList, 4, 10, 1, 1, 10},
+	}}
+	lease, err := client.newLease(ack)
+	if err != nil {
+		t.Fatalf("newLease: %v", err)
+	}
+	if lease.Vendor == nil || lease.Vendor.Vendor != "Cisco AP" {
+		t.Fatalf("option 43 not decoded for the sent vendor class: %v", lease.Vendor)
+	}
+}
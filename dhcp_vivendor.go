@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// IANA private enterprise numbers with built-in decoders
+const (
+	EnterpriseBroadbandForum = 3561
+)
+
+// VIVendorClass is one enterprise entry of option 124 (RFC 3925)
+type VIVendorClass struct {
+	EnterpriseNumber uint32
+	Data             [][]byte
+}
+
+// VIVendorInfo is one enterprise entry of option 125 (RFC 3925)
+type VIVendorInfo struct {
+	EnterpriseNumber uint32
+	SubOptions       []SubOption
+}
+
+// EnterpriseDecoder interprets the sub-options an enterprise defines for
+// option 125
+type EnterpriseDecoder func(subOptions []SubOption) (*VendorInfo, error)
+
+// enterpriseDecoders holds the registered option 125 decoders keyed on the
+// IANA private enterprise number
+var enterpriseDecoders = map[uint32]EnterpriseDecoder{
+	EnterpriseBroadbandForum: decodeBroadbandForumInfo,
+}
+
+// RegisterEnterpriseDecoder registers a decoder for the option 125 data of
+// an enterprise, replacing any existing decoder for it
+func RegisterEnterpriseDecoder(enterpriseNumber uint32, decoder EnterpriseDecoder) {
+	enterpriseDecoders[enterpriseNumber] = decoder
+}
+
+// splitEnterpriseData splits an option 124/125 value into its
+// enterprise-number/data tuples
+func splitEnterpriseData(data []byte, visit func(enterpriseNumber uint32, data []byte) error) error {
+	for i := 0; i < len(data); {
+		if i+5 > len(data) {
+			return fmt.Errorf("truncated enterprise entry at offset %d", i)
+		}
+		enterpriseNumber := binary.BigEndian.Uint32(data[i : i+4])
+		length := int(data[i+4])
+		i += 5
+		if i+length > len(data) {
+			return fmt.Errorf("enterprise %d data needs %d bytes, have %d", enterpriseNumber, length, len(data)-i)
+		}
+		if err := visit(enterpriseNumber, data[i:i+length]); err != nil {
+			return err
+		}
+		i += length
+	}
+	return nil
+}
+
+// appendEnterpriseData appends one enterprise-number/data tuple
+func appendEnterpriseData(data []byte, enterpriseNumber uint32, payload []byte) ([]byte, error) {
+	if len(payload) > 255 {
+		return nil, fmt.Errorf("enterprise %d data too long: %d bytes", enterpriseNumber, len(payload))
+	}
+	data = binary.BigEndian.AppendUint32(data, enterpriseNumber)
+	data = append(data, byte(len(payload)))
+	return append(data, payload...), nil
+}
+
+// DecodeVIVendorClass decodes option 124
+func DecodeVIVendorClass(data []byte) ([]VIVendorClass, error) {
+	var classes []VIVendorClass
+	err := splitEnterpriseData(data, func(enterpriseNumber uint32, payload []byte) error {
+		class := VIVendorClass{EnterpriseNumber: enterpriseNumber}
+		for i := 0; i < len(payload); {
+			length := int(payload[i])
+			if i+1+length > len(payload) {
+				return fmt.Errorf("truncated vendor class data for enterprise %d", enterpriseNumber)
+			}
+			class.Data = append(class.Data, payload[i+1:i+1+length])
+			i += 1 + length
+		}
+		classes = append(classes, class)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return classes, nil
+}
+
+// EncodeVIVendorClass encodes option 124
+func EncodeVIVendorClass(classes []VIVendorClass) ([]byte, error) {
+	var data []byte
+	for _, class := range classes {
+		var payload []byte
+		for _, item := range class.Data {
+			if len(item) > 255 {
+				return nil, fmt.Errorf("vendor class data for enterprise %d too long: %d bytes", class.EnterpriseNumber, len(item))
+			}
+			payload = append(payload, byte(len(item)))
+			payload = append(payload, item...)
+		}
+
+		var err error
+		if data, err = appendEnterpriseData(data, class.EnterpriseNumber, payload); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// DecodeVIVendorInfo decodes option 125
+func DecodeVIVendorInfo(data []byte) ([]VIVendorInfo, error) {
+	var infos []VIVendorInfo
+	err := splitEnterpriseData(data, func(enterpriseNumber uint32, payload []byte) error {
+		subOptions, err := DecodeSubOptions(payload, false)
+		if err != nil {
+			return fmt.Errorf("enterprise %d: %w", enterpriseNumber, err)
+		}
+		infos = append(infos, VIVendorInfo{EnterpriseNumber: enterpriseNumber, SubOptions: subOptions})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// EncodeVIVendorInfo encodes option 125
+func EncodeVIVendorInfo(infos []VIVendorInfo) ([]byte, error) {
+	var data []byte
+	for _, info := range infos {
+		payload, err := EncodeSubOptions(info.SubOptions)
+		if err != nil {
+			return nil, fmt.Errorf("enterprise %d: %w", info.EnterpriseNumber, err)
+		}
+		if data, err = appendEnterpriseData(data, info.EnterpriseNumber, payload); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// Decode interprets the sub-options with the decoder registered for the
+// enterprise, falling back to listing the raw sub-options
+func (v VIVendorInfo) Decode() *VendorInfo {
+	if decoder, exists := enterpriseDecoders[v.EnterpriseNumber]; exists {
+		if info, err := decoder(v.SubOptions); err == nil {
+			return info
+		}
+	}
+
+	info := &VendorInfo{Vendor: fmt.Sprintf("Enterprise %d", v.EnterpriseNumber)}
+	for _, subOption := range v.SubOptions {
+		info.Add(fmt.Sprintf("Sub-option %d", subOption.Code), "%s", opaqueString(subOption.Data))
+	}
+	return info
+}
+
+// String returns a human-readable representation of the vendor class
+func (v VIVendorClass) String() string {
+	var items []string
+	for _, item := range v.Data {
+		items = append(items, opaqueString(item))
+	}
+	return fmt.Sprintf("Enterprise %d: %s", v.EnterpriseNumber, strings.Join(items, ", "))
+}
+
+// decodeBroadbandForumInfo decodes the TR-111 device identity sub-options
+func decodeBroadbandForumInfo(subOptions []SubOption) (*VendorInfo, error) {
+	names := map[byte]string{
+		1: "Device Manufacturer OUI",
+		2: "Device Serial Number",
+		3: "Device Product Class",
+		4: "Gateway Manufacturer OUI",
+		5: "Gateway Serial Number",
+		6: "Gateway Product Class",
+	}
+
+	info := &VendorInfo{Vendor: "Broadband Forum"}
+	for _, subOption := range subOptions {
+		name, known := names[subOption.Code]
+		if !known {
+			name = fmt.Sprintf("Sub-option %d", subOption.Code)
+		}
+		info.Add(name, "%s", opaqueString(subOption.Data))
+	}
+	return info, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVIVendorClassRoundTrip(t *testing.T) {
+	classes := []VIVendorClass{
+		{EnterpriseNumber: EnterpriseBroadbandForum, Data: [][]byte{[]byte("dslforum.org")}},
+		{EnterpriseNumber: 9, Data: [][]byte{[]byte("C9300-48P"), []byte("ztp")}},
+	}
+
+	data, err := EncodeVIVendorClass(classes)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	want := append([]byte{0, 0, 0x0d, 0xe9, 13, 12}, "dslforum.org"...)
+	if !bytes.HasPrefix(data, want) {
+		t.Fatalf("encode: got %v, want prefix %v", data, want)
+	}
+
+	decoded, err := DecodeVIVendorClass(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(decoded) != 2 || decoded[1].EnterpriseNumber != 9 || len(decoded[1].Data) != 2 || string(decoded[1].Data[1]) != "ztp" {
+		t.Fatalf("decode: unexpected result %v", decoded)
+	}
+}
+
+func TestVIVendorInfoUsesEnterpriseDecoder(t *testing.T) {
+	infos := []VIVendorInfo{
+		{EnterpriseNumber: EnterpriseBroadbandForum, SubOptions: []SubOption{
+			{Code: 1, Data: []byte("00D09E")},
+			{Code: 2, Data: []byte("SN123456")},
+		}},
+		{EnterpriseNumber: 4491, SubOptions: []SubOption{{Code: 2, Data: []byte{1, 2}}}},
+	}
+
+	data, err := EncodeVIVendorInfo(infos)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	m := &DHCPMessage{}
+	got := m.optionValueString(OptionVIVendorSpecific, data)
+	for _, want := range []string{"Broadband Forum", "Device Serial Number='SN123456'", "Enterprise 4491", "Sub-option 2=01:02"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestDecodeVIVendorInfoKeepsCode255(t *testing.T) {
+	// Option 125 sub-options have no pad or end
+	infos, err := DecodeVIVendorInfo([]byte{0, 0, 0, 9, 5, 255, 1, 0xaa, 0, 0})
+	if err != nil {
+		t.Fatalf("DecodeVIVendorInfo: %v", err)
+	}
+	if len(infos) != 1 || len(infos[0].SubOptions) != 2 || infos[0].SubOptions[0].Code != 255 || infos[0].SubOptions[1].Code != 0 {
+		t.Fatalf("unexpected sub-options %+v", infos)
+	}
+}
+
+func TestClientSendsConfiguredVIVendorClass(t *testing.T) {
+	classes := []VIVendorClass{{EnterpriseNumber: 9, Data: [][]byte{[]byte("switch")}}}
+	client, err := NewDHCPClientWithConfig([]byte{0x02, 0, 0, 0, 0, 1}, ClientConfig{VIVendorClasses: classes})
+	if err != nil {
+		t.Fatalf("NewDHCPClientWithConfig: %v", err)
+	}
+
+	want, _ := EncodeVIVendorClass(classes)
+	for _, msg := range []*DHCPMessage{client.createDHCPDiscover(), client.createDHCPRequest(&DHCPMessage{Options: map[byte][]byte{}})} {
+		if !bytes.Equal(msg.Options[OptionVIVendorClass], want) {
+			t.Errorf("option 124: got %v, want %v", msg.Options[OptionVIVendorClass], want)
+		}
+	}
+}
+
+func TestMalformedVIVendorInfoKeepsLease(t *testing.T) {
+	ack := &DHCPMessage{YourIP: 0x0a000005, Options: map[byte][]byte{
+		// Enterprise 9 claims 10 bytes of data but carries 2
+		OptionVIVendorSpecific: {0, 0, 0, 9, 10, 1, 0},
+	}}
+
+	lease, err := NewLease(ack)
+	if err != nil {
+		t.Fatalf("NewLease: %v", err)
+	}
+	if lease.VIVendorError == nil || lease.VIVendorInfo != nil {
+		t.Fatalf("expected a recorded decode error, got %v and %v", lease.VIVendorError, lease.VIVendorInfo)
+	}
+	if !strings.Contains(lease.String(), "Undecodable V-I Vendor Settings") {
+		t.Fatalf("decode error missing from %q", lease.String())
+	}
+}
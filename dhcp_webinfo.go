@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// CaptivePortalUnrestricted is the option 114 value a network uses to
+// say it has no captive portal (RFC 8910 section 2)
+const CaptivePortalUnrestricted = "urn:ietf:params:capport:unrestricted"
+
+// Media types of the captive portal API and PAC files
+const (
+	MediaTypeCaptivePortal   = "application/captive+json"
+	MediaTypeProxyAutoConfig = "application/x-ns-proxy-autoconfig"
+)
+
+// pacMediaTypes are the media types PAC files are served with in
+// practice. Servers rarely use MediaTypeProxyAutoConfig, so any of these
+// is accepted; an HTML page, such as a captive portal login, is not.
+var pacMediaTypes = []string{
+	MediaTypeProxyAutoConfig,
+	"application/x-javascript-config",
+	"application/javascript",
+	"application/x-javascript",
+	"text/javascript",
+	"text/plain",
+	"application/octet-stream",
+}
+
+// maxWebInfoSize bounds the size of a fetched portal response or PAC file
+const maxWebInfoSize = 1 << 20
+
+// CaptivePortalStatus is the captive portal API response (RFC 8908
+// section 5)
+type CaptivePortalStatus struct {
+	Captive          bool   `json:"captive"`
+	UserPortalURL    string `json:"user-portal-url,omitempty"`
+	VenueInfoURL     string `json:"venue-info-url,omitempty"`
+	CanExtendSession bool   `json:"can-extend-session,omitempty"`
+	SecondsRemaining int64  `json:"seconds-remaining,omitempty"`
+	BytesRemaining   int64  `json:"bytes-remaining,omitempty"`
+}
+
+// String returns a human-readable summary of the status
+func (s *CaptivePortalStatus) String() string {
+	if !s.Captive {
+		return "not captive"
+	}
+	result := "captive"
+	if s.UserPortalURL != "" {
+		result += ", portal " + s.UserPortalURL
+	}
+	if s.SecondsRemaining > 0 {
+		result += fmt.Sprintf(", %s remaining", time.Duration(s.SecondsRemaining)*time.Second)
+	}
+	return result
+}
+
+// WebInfoFetcher retrieves the captive portal API and PAC file a lease
+// points to
+type WebInfoFetcher struct {
+	// Client performs the requests. Nil uses a client with a 10 second
+	// timeout.
+	Client *http.Client
+}
+
+// client returns the HTTP client to use
+func (f *WebInfoFetcher) client() *http.Client {
+	if f.Client != nil {
+		return f.Client
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// FetchCaptivePortal queries the captive portal API at uri. RFC 8908
+// requires the API to be served over HTTPS.
+func (f *WebInfoFetcher) FetchCaptivePortal(uri string) (*CaptivePortalStatus, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid captive portal URI: %w", err)
+	}
+	if parsed.Scheme != "https" {
+		return nil, fmt.Errorf("captive portal API must use https, got %q", parsed.Scheme)
+	}
+
+	body, err := f.get(uri, MediaTypeCaptivePortal)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &CaptivePortalStatus{}
+	if err := json.Unmarshal(body, status); err != nil {
+		return nil, fmt.Errorf("invalid captive portal response: %w", err)
+	}
+	return status, nil
+}
+
+// FetchProxyAutoConfig downloads the PAC file at pacURL
+func (f *WebInfoFetcher) FetchProxyAutoConfig(pacURL string) (string, error) {
+	parsed, err := url.Parse(pacURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid PAC URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("unsupported PAC URL scheme %q", parsed.Scheme)
+	}
+
+	body, err := f.get(pacURL, MediaTypeProxyAutoConfig, pacMediaTypes...)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// get fetches a resource, asking for mediaType. The response must be of
+// mediaType or, when given, one of the accepted media types.
+func (f *WebInfoFetcher) get(resource, mediaType string, accepted ...string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, resource, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", mediaType)
+
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", resource, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", resource, resp.Status)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		got, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (got != mediaType && !slices.Contains(accepted, got)) {
+			return nil, fmt.Errorf("unexpected content type %q from %s", contentType, resource)
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWebInfoSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", resource, err)
+	}
+	if len(body) > maxWebInfoSize {
+		return nil, fmt.Errorf("response from %s exceeds %d bytes", resource, maxWebInfoSize)
+	}
+	return body, nil
+}
+
+// fetchWebInfo retrieves the captive portal status and PAC file when
+// configured. Failures are reported but do not affect the lease.
+func (c *DHCPClient) fetchWebInfo() {
+	fetcher := &WebInfoFetcher{Client: c.config.HTTPClient}
+	c.captivePortal = nil
+	c.proxyAutoConfig = ""
+
+	if c.config.FetchCaptivePortal && c.lease.HasCaptivePortal() {
+		if status, err := fetcher.FetchCaptivePortal(c.lease.CaptivePortal); err != nil {
+			fmt.Printf("Captive portal check failed: %v\n", err)
+		} else {
+			c.captivePortal = status
+			fmt.Printf("Captive portal: %s\n", status)
+		}
+	}
+
+	if c.config.FetchProxyAutoConfig && c.lease.ProxyAutoConfig != "" {
+		if pac, err := fetcher.FetchProxyAutoConfig(c.lease.ProxyAutoConfig); err != nil {
+			fmt.Printf("PAC download failed: %v\n", err)
+		} else {
+			c.proxyAutoConfig = pac
+			fmt.Printf("Downloaded PAC file (%d bytes)\n", len(pac))
+		}
+	}
+}
+
+// CaptivePortal returns the captive portal status fetched for the current
+// lease, or nil if none was fetched
+func (c *DHCPClient) CaptivePortal() *CaptivePortalStatus {
+	return c.captivePortal
+}
+
+// ProxyAutoConfig returns the PAC file fetched for the current lease, or
+// an empty string if none was fetched
+func (c *DHCPClient) ProxyAutoConfig() string {
+	return c.proxyAutoConfig
+}
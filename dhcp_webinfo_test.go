@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLeaseWebInfoOptions(t *testing.T) {
+	msg := &DHCPMessage{Options: map[byte][]byte{
+		OptionCaptivePortal:   []byte("https://portal.example.net/api"),
+		OptionProxyAutoConfig: []byte("http://wpad.example.net/wpad.dat\x00"),
+	}}
+
+	lease, err := NewLease(msg)
+	if err != nil {
+		t.Fatalf("NewLease: %v", err)
+	}
+	if lease.CaptivePortal != "https://portal.example.net/api" || !lease.HasCaptivePortal() {
+		t.Fatalf("captive portal = %q", lease.CaptivePortal)
+	}
+	if lease.ProxyAutoConfig != "http://wpad.example.net/wpad.dat" {
+		t.Fatalf("PAC URL = %q", lease.ProxyAutoConfig)
+	}
+
+	msg.Options[OptionCaptivePortal] = []byte(CaptivePortalUnrestricted)
+	lease, _ = NewLease(msg)
+	if lease.HasCaptivePortal() {
+		t.Fatal("unrestricted network reported as captive")
+	}
+}
+
+func TestFetchCaptivePortal(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != MediaTypeCaptivePortal {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", MediaTypeCaptivePortal)
+		w.Write([]byte(`{"captive": true, "user-portal-url": "https://portal.example.net/login", "seconds-remaining": 600}`))
+	}))
+	defer server.Close()
+
+	fetcher := &WebInfoFetcher{Client: server.Client()}
+	status, err := fetcher.FetchCaptivePortal(server.URL + "/api")
+	if err != nil {
+		t.Fatalf("FetchCaptivePortal: %v", err)
+	}
+	if !status.Captive || status.UserPortalURL != "https://portal.example.net/login" || status.SecondsRemaining != 600 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	if _, err := fetcher.FetchCaptivePortal("http://portal.example.net/api"); err == nil {
+		t.Fatal("expected plain HTTP captive portal API to be rejected")
+	}
+}
+
+func TestFetchProxyAutoConfig(t *testing.T) {
+	const pac = `function FindProxyForURL(url, host) { return "DIRECT"; }`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wpad.dat":
+			w.Header().Set("Content-Type", MediaTypeProxyAutoConfig)
+			w.Write([]byte(pac))
+		case "/proxy.pac":
+			w.Header().Set("Content-Type", "application/x-javascript-config; charset=utf-8")
+			w.Write([]byte(pac))
+		case "/wrong-type":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := &WebInfoFetcher{Client: server.Client()}
+	got, err := fetcher.FetchProxyAutoConfig(server.URL + "/wpad.dat")
+	if err != nil {
+		t.Fatalf("FetchProxyAutoConfig: %v", err)
+	}
+	if got != pac {
+		t.Fatalf("got %q, want %q", got, pac)
+	}
+
+	if got, err := fetcher.FetchProxyAutoConfig(server.URL + "/proxy.pac"); err != nil || got != pac {
+		t.Fatalf("PAC served as application/x-javascript-config: got %q, %v", got, err)
+	}
+
+	for _, path := range []string{"/wrong-type", "/missing"} {
+		if _, err := fetcher.FetchProxyAutoConfig(server.URL + path); err == nil {
+			t.Errorf("expected error fetching %s", path)
+		}
+	}
+}
+
+func TestFetchWebInfoStoresResults(t *testing.T) {
+	const pac = `function FindProxyForURL(url, host) { return "DIRECT"; }`
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api":
+			w.Header().Set("Content-Type", MediaTypeCaptivePortal)
+			w.Write([]byte(`{"captive": false}`))
+		case "/wpad.dat":
+			w.Header().Set("Content-Type", MediaTypeProxyAutoConfig)
+			w.Write([]byte(pac))
+		}
+	}))
+	defer server.Close()
+
+	client := &DHCPClient{
+		config: ClientConfig{FetchCaptivePortal: true, FetchProxyAutoConfig: true, HTTPClient: server.Client()},
+		lease:  &Lease{CaptivePortal: server.URL + "/api", ProxyAutoConfig: server.URL + "/wpad.dat"},
+	}
+	client.fetchWebInfo()
+
+	if status := client.CaptivePortal(); status == nil || status.Captive {
+		t.Fatalf("unexpected captive portal status %+v", status)
+	}
+	if got := client.ProxyAutoConfig(); got != pac {
+		t.Fatalf("got PAC %q, want %q", got, pac)
+	}
+
+	// A lease without the options clears the previous results
+	client.lease = &Lease{}
+	client.fetchWebInfo()
+	if client.CaptivePortal() != nil || client.ProxyAutoConfig() != "" {
+		t.Fatal("results of the previous lease were kept")
+	}
+}
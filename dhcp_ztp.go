@@ -0,0 +1,240 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Download limits used when ProvisioningConfig leaves them unset
+const (
+	DefaultArtifactTimeout = time.Minute
+	DefaultMaxArtifactSize = 64 << 20
+)
+
+// DecodeSZTPRedirect decodes the bootstrap server URIs of option 143
+// (RFC 8572 section 8.1). Each URI is preceded by its 2-byte length.
+func DecodeSZTPRedirect(data []byte) ([]string, error) {
+	var uris []string
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, fmt.Errorf("truncated URI length")
+		}
+		length := int(binary.BigEndian.Uint16(data))
+		data = data[2:]
+		if length == 0 || length > len(data) {
+			return nil, fmt.Errorf("invalid URI length %d with %d bytes left", length, len(data))
+		}
+		uris = append(uris, string(data[:length]))
+		data = data[length:]
+	}
+	return uris, nil
+}
+
+// EncodeSZTPRedirect encodes bootstrap server URIs as option 143
+func EncodeSZTPRedirect(uris []string) ([]byte, error) {
+	var data []byte
+	for _, uri := range uris {
+		if len(uri) == 0 || len(uri) > 0xffff {
+			return nil, fmt.Errorf("invalid URI length %d", len(uri))
+		}
+		data = binary.BigEndian.AppendUint16(data, uint16(len(uri)))
+		data = append(data, uri...)
+	}
+	return data, nil
+}
+
+// configFileURL returns the configuration file URL network devices fetch
+// during zero-touch provisioning. Only an option 67 holding a complete
+// http, https or tftp URL counts: a plain file name is an ordinary PXE
+// boot file.
+func configFileURL(msg *DHCPMessage) string {
+	bootfile := msg.bytesToString(msg.Options[OptionBootfileName])
+	parsed, err := url.Parse(bootfile)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	switch parsed.Scheme {
+	case "http", "https", "tftp":
+		return bootfile
+	default:
+		return ""
+	}
+}
+
+// ProvisioningConfig configures downloading of zero-touch provisioning
+// artifacts
+type ProvisioningConfig struct {
+	// StagingDir is where artifacts are downloaded. Provisioning is
+	// disabled when it is empty.
+	StagingDir string
+	// Checksums maps artifact URLs to their expected hex SHA-256 digests
+	Checksums map[string]string
+	// RequireChecksum rejects artifacts without an entry in Checksums
+	RequireChecksum bool
+	// TrustLeaseArtifacts stages the artifact named in the lease without
+	// a configured checksum. Any host on the link can answer DHCP, so by
+	// default that artifact must be listed in Checksums.
+	TrustLeaseArtifacts bool
+	// MaxSize is the largest artifact accepted. Zero means
+	// DefaultMaxArtifactSize.
+	MaxSize int64
+	// HTTPClient fetches http and https URLs. Nil uses a client with a
+	// DefaultArtifactTimeout timeout.
+	HTTPClient *http.Client
+	// TFTP fetches tftp URLs
+	TFTP TFTPClient
+}
+
+// StagedArtifact is an artifact downloaded to the staging directory
+type StagedArtifact struct {
+	URL    string
+	Path   string
+	SHA256 string
+}
+
+// Stage downloads the artifact at rawURL into the staging directory,
+// verifying its SHA-256 digest when one is known. The file only appears
+// under its final name once verified.
+func (cfg *ProvisioningConfig) Stage(rawURL string) (*StagedArtifact, error) {
+	expected := strings.ToLower(cfg.Checksums[rawURL])
+	if expected == "" && cfg.RequireChecksum {
+		return nil, fmt.Errorf("no checksum configured for %s", rawURL)
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact URL: %w", err)
+	}
+	name := path.Base(parsed.Path)
+	if name == "." || name == "/" {
+		return nil, fmt.Errorf("artifact URL %s has no file name", rawURL)
+	}
+
+	tmp, err := os.CreateTemp(cfg.StagingDir, "."+name+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	if err := cfg.download(parsed, io.MultiWriter(tmp, hash)); err != nil {
+		return nil, err
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+	if expected != "" && digest != expected {
+		return nil, fmt.Errorf("checksum mismatch for %s: got %s, want %s", rawURL, digest, expected)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write staging file: %w", err)
+	}
+	final := filepath.Join(cfg.StagingDir, name)
+	if err := os.Rename(tmp.Name(), final); err != nil {
+		return nil, fmt.Errorf("failed to stage %s: %w", name, err)
+	}
+
+	return &StagedArtifact{URL: rawURL, Path: final, SHA256: digest}, nil
+}
+
+// download fetches an artifact over HTTP(S) or TFTP
+func (cfg *ProvisioningConfig) download(artifact *url.URL, w io.Writer) error {
+	switch artifact.Scheme {
+	case "http", "https":
+		client := cfg.HTTPClient
+		if client == nil {
+			client = &http.Client{Timeout: DefaultArtifactTimeout}
+		}
+		resp, err := client.Get(artifact.String())
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", artifact, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to fetch %s: %s", artifact, resp.Status)
+		}
+		if resp.ContentLength > cfg.maxSize() {
+			return fmt.Errorf("%s is %d bytes, more than the %d byte limit", artifact, resp.ContentLength, cfg.maxSize())
+		}
+		n, err := io.Copy(w, io.LimitReader(resp.Body, cfg.maxSize()+1))
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", artifact, err)
+		}
+		if n > cfg.maxSize() {
+			return fmt.Errorf("%s exceeds the %d byte limit", artifact, cfg.maxSize())
+		}
+		return nil
+	case "tftp":
+		server := artifact.Host
+		if artifact.Port() == "" {
+			server = net.JoinHostPort(artifact.Hostname(), strconv.Itoa(tftpPort))
+		}
+		limited := &limitedWriter{w: w, remaining: cfg.maxSize()}
+		_, err := cfg.TFTP.Fetch(server, strings.TrimPrefix(artifact.Path, "/"), limited)
+		return err
+	default:
+		return fmt.Errorf("unsupported artifact URL scheme %q", artifact.Scheme)
+	}
+}
+
+// maxSize returns the configured artifact size limit
+func (cfg *ProvisioningConfig) maxSize() int64 {
+	if cfg.MaxSize == 0 {
+		return DefaultMaxArtifactSize
+	}
+	return cfg.MaxSize
+}
+
+// limitedWriter fails writes past the size limit
+type limitedWriter struct {
+	w         io.Writer
+	remaining int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining {
+		return 0, fmt.Errorf("artifact exceeds the size limit")
+	}
+	l.remaining -= int64(len(p))
+	return l.w.Write(p)
+}
+
+// provision stages the configuration file named by the lease when
+// provisioning is configured. Failures are reported but do not affect the
+// lease.
+func (c *DHCPClient) provision() {
+	cfg := &c.config.Provisioning
+	if cfg.StagingDir == "" {
+		return
+	}
+
+	for _, uri := range c.lease.SZTPBootstrapServers {
+		fmt.Printf("SZTP bootstrap server: %s\n", uri)
+	}
+	if c.lease.ConfigURL == "" {
+		return
+	}
+
+	staging := *cfg
+	if !cfg.TrustLeaseArtifacts {
+		staging.RequireChecksum = true
+	}
+	artifact, err := staging.Stage(c.lease.ConfigURL)
+	if err != nil {
+		fmt.Printf("Provisioning failed: %v\n", err)
+		return
+	}
+	fmt.Printf("Staged %s at %s (sha256 %s)\n", artifact.URL, artifact.Path, artifact.SHA256)
+}
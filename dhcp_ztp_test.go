@@ -0,0 +1,163 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSZTPRedirectRoundTrip(t *testing.T) {
+	uris := []string{"https://bootstrap1.example.net:8443", "https://[2001:db8::1]/sztp"}
+
+	data, err := EncodeSZTPRedirect(uris)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := DecodeSZTPRedirect(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !reflect.DeepEqual(got, uris) {
+		t.Fatalf("got %v, want %v", got, uris)
+	}
+
+	if _, err := DecodeSZTPRedirect([]byte{0, 10, 'h', 't'}); err == nil {
+		t.Fatal("expected error for truncated URI")
+	}
+}
+
+func TestConfigFileURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[byte][]byte
+		siaddr  uint32
+		want    string
+	}{
+		{"full URL in 67", map[byte][]byte{OptionBootfileName: []byte("http://10.0.0.1/ztp/switch.cfg")}, 0, "http://10.0.0.1/ztp/switch.cfg"},
+		{"TFTP URL in 67", map[byte][]byte{OptionBootfileName: []byte("tftp://10.0.0.2/a.cfg")}, 0, "tftp://10.0.0.2/a.cfg"},
+		{"unsupported scheme", map[byte][]byte{OptionBootfileName: []byte("ftp://10.0.0.2/a.cfg")}, 0, ""},
+		{"PXE boot file", map[byte][]byte{OptionTFTPServerName: []byte("10.0.0.2"), OptionBootfileName: []byte("pxelinux.0")}, 0x0a000003, ""},
+		{"no file", map[byte][]byte{OptionTFTPServerName: []byte("10.0.0.2")}, 0, ""},
+	}
+	for _, tt := range tests {
+		msg := &DHCPMessage{NextServerIP: tt.siaddr, Options: tt.options}
+		if got := configFileURL(msg); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStageArtifact(t *testing.T) {
+	content := []byte("hostname switch1\n")
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	cfg := &ProvisioningConfig{
+		StagingDir:      dir,
+		Checksums:       map[string]string{server.URL + "/switch.cfg": digest},
+		RequireChecksum: true,
+	}
+
+	artifact, err := cfg.Stage(server.URL + "/switch.cfg")
+	if err != nil {
+		t.Fatalf("Stage: %v", err)
+	}
+	if artifact.Path != filepath.Join(dir, "switch.cfg") || artifact.SHA256 != digest {
+		t.Fatalf("unexpected artifact %+v", artifact)
+	}
+	if got, _ := os.ReadFile(artifact.Path); string(got) != string(content) {
+		t.Fatalf("staged content %q", got)
+	}
+
+	// A checksum mismatch leaves nothing in the staging directory
+	cfg.Checksums[server.URL+"/bad.cfg"] = hex.EncodeToString(make([]byte, sha256.Size))
+	if _, err := cfg.Stage(server.URL + "/bad.cfg"); err == nil {
+		t.Fatal("expected checksum mismatch")
+	}
+	if _, err := cfg.Stage(server.URL + "/unknown.cfg"); err == nil {
+		t.Fatal("expected error for artifact without checksum")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("staging directory has %d entries, want 1", len(entries))
+	}
+}
+
+func TestStageArtifactOverTFTP(t *testing.T) {
+	content := testFile(3000)
+	server := startTestTFTPServer(t, map[string][]byte{"configs/a.cfg": content}, false)
+	host, port, _ := net.SplitHostPort(server.addr())
+
+	cfg := &ProvisioningConfig{StagingDir: t.TempDir(), TFTP: TFTPClient{Timeout: time.Second}}
+	artifact, err := cfg.Stage("tftp://" + net.JoinHostPort(host, port) + "/configs/a.cfg")
+	if err != nil {
+		t.Fatalf("Stage: %v", err)
+	}
+	if got, _ := os.ReadFile(artifact.Path); len(got) != len(content) {
+		t.Fatalf("staged %d bytes, want %d", len(got), len(content))
+	}
+}
+
+func TestStageArtifactSizeLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Chunked, so the limit is enforced while reading
+		w.(http.Flusher).Flush()
+		w.Write(make([]byte, 2048))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	cfg := &ProvisioningConfig{StagingDir: dir, MaxSize: 1024}
+	if _, err := cfg.Stage(server.URL + "/big.img"); err == nil {
+		t.Fatal("expected an error for an oversized artifact")
+	}
+
+	content := testFile(3000)
+	tftpServer := startTestTFTPServer(t, map[string][]byte{"big.img": content}, false)
+	cfg.TFTP = TFTPClient{Timeout: time.Second}
+	if _, err := cfg.Stage("tftp://" + tftpServer.addr() + "/big.img"); err == nil {
+		t.Fatal("expected an error for an oversized TFTP artifact")
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("staging directory has %d entries, want 0", len(entries))
+	}
+}
+
+func TestProvisionRequiresChecksumForLeaseArtifact(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hostname switch1\n"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := &DHCPClient{
+		config: ClientConfig{Provisioning: ProvisioningConfig{StagingDir: dir}},
+		lease:  &Lease{ConfigURL: server.URL + "/switch.cfg"},
+	}
+
+	// A failure is reported without failing the bind
+	client.provision()
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatal("unverified artifact was staged")
+	}
+
+	client.config.Provisioning.TrustLeaseArtifacts = true
+	client.provision()
+	if _, err := os.Stat(filepath.Join(dir, "switch.cfg")); err != nil {
+		t.Fatalf("trusted artifact not staged: %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+//go:build ignore
+
+// gen_options.go generates dhcp_option_table.go from the vendored IANA
+// BOOTP/DHCP parameters registry in iana/options.csv.
+//
+// Run it with `go generate` from the repository root.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	inputFile  = "iana/options.csv"
+	outputFile = "dhcp_option_table.go"
+)
+
+// optionTypes maps option codes to the data type used when encoding and
+// decoding them. The IANA registry only records a data length, so this
+// table is maintained by hand. Codes not listed here are treated as opaque
+// bytes.
+var optionTypes = map[int]string{
+	0:   "OptionTypeNone",
+	1:   "OptionTypeIP",
+	2:   "OptionTypeInt32",
+	3:   "OptionTypeIPList",
+	4:   "OptionTypeIPList",
+	5:   "OptionTypeIPList",
+	6:   "OptionTypeIPList",
+	7:   "OptionTypeIPList",
+	8:   "OptionTypeIPList",
+	9:   "OptionTypeIPList",
+	10:  "OptionTypeIPList",
+	11:  "OptionTypeIPList",
+	12:  "OptionTypeString",
+	13:  "OptionTypeUint16",
+	14:  "OptionTypeString",
+	15:  "OptionTypeString",
+	16:  "OptionTypeIP",
+	17:  "OptionTypeString",
+	18:  "OptionTypeString",
+	19:  "OptionTypeBool",
+	20:  "OptionTypeBool",
+	21:  "OptionTypeIPPairs",
+	22:  "OptionTypeUint16",
+	23:  "OptionTypeUint8",
+	24:  "OptionTypeDuration",
+	25:  "OptionTypeUint16List",
+	26:  "OptionTypeUint16",
+	27:  "OptionTypeBool",
+	28:  "OptionTypeIP",
+	29:  "OptionTypeBool",
+	30:  "OptionTypeBool",
+	31:  "OptionTypeBool",
+	32:  "OptionTypeIP",
+	33:  "OptionTypeIPPairs",
+	34:  "OptionTypeBool",
+	35:  "OptionTypeDuration",
+	36:  "OptionTypeBool",
+	37:  "OptionTypeUint8",
+	38:  "OptionTypeDuration",
+	39:  "OptionTypeBool",
+	40:  "OptionTypeString",
+	41:  "OptionTypeIPList",
+	42:  "OptionTypeIPList",
+	43:  "OptionTypeVendorSpecific",
+	44:  "OptionTypeIPList",
+	45:  "OptionTypeIPList",
+	46:  "OptionTypeUint8",
+	47:  "OptionTypeString",
+	48:  "OptionTypeIPList",
+	49:  "OptionTypeIPList",
+	50:  "OptionTypeIP",
+	51:  "OptionTypeDuration",
+	52:  "OptionTypeUint8",
+	53:  "OptionTypeMessageType",
+	54:  "OptionTypeIP",
+	55:  "OptionTypeCodeList",
+	56:  "OptionTypeString",
+	57:  "OptionTypeUint16",
+	58:  "OptionTypeDuration",
+	59:  "OptionTypeDuration",
+	60:  "OptionTypeString",
+	61:  "OptionTypeClientID",
+	62:  "OptionTypeString",
+	64:  "OptionTypeString",
+	65:  "OptionTypeIPList",
+	66:  "OptionTypeString",
+	67:  "OptionTypeString",
+	68:  "OptionTypeIPList",
+	69:  "OptionTypeIPList",
+	70:  "OptionTypeIPList",
+	71:  "OptionTypeIPList",
+	72:  "OptionTypeIPList",
+	73:  "OptionTypeIPList",
+	74:  "OptionTypeIPList",
+	75:  "OptionTypeIPList",
+	76:  "OptionTypeIPList",
+	77:  "OptionTypeUserClass",
+	80:  "OptionTypeNone",
+	81:  "OptionTypeClientFQDN",
+	82:  "OptionTypeRelayAgentInfo",
+	85:  "OptionTypeIPList",
+	86:  "OptionTypeString",
+	87:  "OptionTypeString",
+	88:  "OptionTypeDomainList",
+	89:  "OptionTypeIPList",
+	90:  "OptionTypeAuthentication",
+	91:  "OptionTypeUint32",
+	92:  "OptionTypeIPList",
+	93:  "OptionTypeUint16List",
+	100: "OptionTypeString",
+	101: "OptionTypeString",
+	108: "OptionTypeDuration",
+	112: "OptionTypeIPList",
+	113: "OptionTypeString",
+	114: "OptionTypeString",
+	116: "OptionTypeBool",
+	117: "OptionTypeUint16List",
+	118: "OptionTypeIP",
+	119: "OptionTypeDomainList",
+	121: "OptionTypeClasslessRoutes",
+	124: "OptionTypeVIVendorClass",
+	125: "OptionTypeVIVendorInfo",
+	138: "OptionTypeIPList",
+	141: "OptionTypeDomainList",
+	143: "OptionTypeURIList",
+	150: "OptionTypeIPList",
+	152: "OptionTypeUint32",
+	153: "OptionTypeDuration",
+	154: "OptionTypeUint32",
+	155: "OptionTypeUint32",
+	156: "OptionTypeUint8",
+	157: "OptionTypeUint8",
+	161: "OptionTypeString",
+	208: "OptionTypeBytes",
+	209: "OptionTypeString",
+	210: "OptionTypeString",
+	211: "OptionTypeDuration",
+	249: "OptionTypeClasslessRoutes",
+	252: "OptionTypeString",
+	255: "OptionTypeNone",
+}
+
+// privateUseNames names widely deployed options from the private use range
+// (224-254), which IANA leaves unnamed.
+var privateUseNames = map[int]string{
+	249: "Private/Classless Static Route (Microsoft)",
+	252: "Private/Proxy autodiscovery",
+}
+
+var referencePattern = regexp.MustCompile(`\[([^\]]+)\]`)
+
+type option struct {
+	code      int
+	name      string
+	reference string
+	typ       string
+}
+
+func main() {
+	f, err := os.Open(inputFile)
+	if err != nil {
+		log.Fatalf("open %s: %v", inputFile, err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		log.Fatalf("parse %s: %v", inputFile, err)
+	}
+	if len(records) < 2 {
+		log.Fatalf("%s has no option records", inputFile)
+	}
+
+	var options [256]*option
+	for _, record := range records[1:] {
+		if len(record) < 5 {
+			log.Fatalf("malformed record: %q", record)
+		}
+
+		first, last, err := parseTag(record[0])
+		if err != nil {
+			log.Fatalf("record %q: %v", record, err)
+		}
+
+		// Some registry names span several lines; the first one is the
+		// primary assignment.
+		name := strings.TrimSpace(strings.SplitN(record[1], "\n", 2)[0])
+		reference := parseReference(record[4])
+
+		for code := first; code <= last; code++ {
+			opt := &option{code: code, name: name, reference: reference, typ: "OptionTypeBytes"}
+			if override, ok := privateUseNames[code]; ok {
+				opt.name = override
+			}
+			if typ, ok := optionTypes[code]; ok {
+				opt.typ = typ
+			}
+			options[code] = opt
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gen_options.go from %s; DO NOT EDIT.\n\n", inputFile)
+	fmt.Fprintf(&buf, "package main\n\n")
+	fmt.Fprintf(&buf, "// optionTable describes every DHCP option code in the IANA registry.\n")
+	fmt.Fprintf(&buf, "var optionTable = [256]OptionInfo{\n")
+	for code, opt := range options {
+		if opt == nil {
+			log.Fatalf("option %d missing from %s", code, inputFile)
+		}
+		fmt.Fprintf(&buf, "\t%d: {Code: %d, Name: %q, Reference: %q, Type: %s},\n",
+			opt.code, opt.code, opt.name, opt.reference, opt.typ)
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("format generated code: %v", err)
+	}
+
+	if err := os.WriteFile(outputFile, src, 0o644); err != nil {
+		log.Fatalf("write %s: %v", outputFile, err)
+	}
+}
+
+// parseTag parses a registry tag, which is either a single code or an
+// inclusive range such as "102-107".
+func parseTag(tag string) (int, int, error) {
+	lo, hi, isRange := strings.Cut(strings.TrimSpace(tag), "-")
+	first, err := strconv.Atoi(lo)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid tag %q: %w", tag, err)
+	}
+
+	last := first
+	if isRange {
+		if last, err = strconv.Atoi(hi); err != nil {
+			return 0, 0, fmt.Errorf("invalid tag %q: %w", tag, err)
+		}
+	}
+
+	if first < 0 || last > 255 || first > last {
+		return 0, 0, fmt.Errorf("tag %q out of range", tag)
+	}
+
+	return first, last, nil
+}
+
+// parseReference turns "[RFC3046][RFC3527]" into "RFC 3046, RFC 3527".
+func parseReference(field string) string {
+	var refs []string
+	for _, match := range referencePattern.FindAllStringSubmatch(field, -1) {
+		ref := match[1]
+		if strings.HasPrefix(ref, "RFC") {
+			ref = "RFC " + strings.TrimPrefix(ref, "RFC")
+		}
+		refs = append(refs, ref)
+	}
+	return strings.Join(refs, ", ")
+}
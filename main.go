@@ -1,18 +1,79 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"net/netip"
+	"os"
+	"strings"
 )
 
+// prefixList is a repeatable flag of addresses or CIDR prefixes
+type prefixList []netip.Prefix
+
+func (l *prefixList) String() string {
+	prefixes := make([]string, len(*l))
+	for i, prefix := range *l {
+		prefixes[i] = prefix.String()
+	}
+	return strings.Join(prefixes, ",")
+}
+
+func (l *prefixList) Set(value string) error {
+	if !strings.Contains(value, "/") {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return err
+		}
+		*l = append(*l, netip.PrefixFrom(addr, addr.BitLen()))
+		return nil
+	}
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, prefix.Masked())
+	return nil
+}
+
 func main() {
+	var servers ServerPolicy
+	iface := flag.String("interface", "", "network interface the client runs on")
+	network := flag.String("network", "", "identifier of the attached network, e.g. the Wi-Fi SSID, which selects the stable MAC address")
+	secretPath := flag.String("mac-secret", DefaultMACSecretPath, "file holding the secret stable MAC addresses are derived from, created if missing")
+	detect := flag.Duration("detect", 0, "report every DHCP server that answers within this window instead of requesting an address")
+	flag.Var((*prefixList)(&servers.AllowedServerIDs), "allow-server", "accept only servers whose identifier is this address or in this prefix (repeatable)")
+	flag.Var((*prefixList)(&servers.DeniedServerIDs), "deny-server", "reject servers whose identifier is this address or in this prefix (repeatable)")
+	flag.Parse()
+
 	fmt.Println("DHCP client starting...")
 
-	// Create a MAC address for testing
-	macAddr := []byte{0x62, 0xf9, 0xb8, 0xfc, 0x9d, 0xff}
+	secret, err := LoadOrCreateMACSecret(*secretPath)
+	if err != nil {
+		log.Fatalf("Failed to load MAC secret: %v", err)
+	}
+
+	// Create and start the DHCP client with a locally administered MAC
+	// address that stays the same across restarts on the same network
+	client, err := NewDHCPClientWithConfig(nil, ClientConfig{
+		Interface: *iface,
+		MAC:       MACConfig{Mode: MACModeStable, Secret: secret, NetworkID: *network},
+		Servers:   servers,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create DHCP client: %v", err)
+	}
 
-	// Create and start the DHCP client
-	client := NewDHCPClient(macAddr)
+	if *detect > 0 {
+		report, err := client.DetectServers(*detect)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Server detection failed: %v\n", err)
+			os.Exit(DetectUnknown)
+		}
+		fmt.Print(report.String())
+		os.Exit(report.ExitCode())
+	}
 
 	if err := client.Start(); err != nil {
 		log.Fatalf("DHCP process failed: %v", err)